@@ -0,0 +1,138 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package digest
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"hash"
+	"strings"
+)
+
+//
+//  Digesters
+//
+
+var digesters = map[string]MessageDigester{}
+
+// SetDigester registers a message digester with algorithm name
+//
+// Names are case-insensitive, "-" and "_" are ignored, e.g. "SHA-256" == "sha256"
+func SetDigester(algorithm string, digester MessageDigester) {
+	digesters[digestName(algorithm)] = digester
+}
+
+// GetDigester returns the message digester for algorithm
+//
+// Falls back to the standard library for "md5", "sha1", "sha224", "sha256", "sha384" and "sha512"
+// when no digester registered (nil if not supported)
+func GetDigester(algorithm string) MessageDigester {
+	name := digestName(algorithm)
+	if digester := digesters[name]; digester != nil {
+		return digester
+	}
+	switch name {
+	case "md5":
+		return hashDigester(md5.New)
+	case "sha1":
+		return hashDigester(sha1.New)
+	case "sha224":
+		return hashDigester(sha256.New224)
+	case "sha256":
+		return hashDigester(sha256.New)
+	case "sha384":
+		return hashDigester(sha512.New384)
+	case "sha512":
+		return hashDigester(sha512.New)
+	}
+	return nil
+}
+
+func digestName(algorithm string) string {
+	name := strings.ToLower(strings.TrimSpace(algorithm))
+	name = strings.ReplaceAll(name, "-", "")
+	return strings.ReplaceAll(name, "_", "")
+}
+
+type hashDigester func() hash.Hash
+
+// Override
+func (fn hashDigester) Digest(data []byte) []byte {
+	h := fn()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+//
+//  Content Digest
+//
+
+// ContentDigest computes an algorithm-tagged digest string
+//
+//	Format: "{ALGORITHM}:{HEX_ENCODE}", e.g. "sha256:2cf24dba5fb0a30e..."
+//
+// Returns: empty string if the algorithm not supported
+func ContentDigest(algorithm string, data []byte) string {
+	digester := GetDigester(algorithm)
+	if digester == nil {
+		return ""
+	}
+	return digestName(algorithm) + ":" + hex.EncodeToString(digester.Digest(data))
+}
+
+// ParseContentDigest splits an algorithm-tagged digest string
+//
+// Returns: algorithm name, binary digest, false if the string is malformed
+func ParseContentDigest(str string) (string, []byte, bool) {
+	pos := strings.IndexByte(str, ':')
+	if pos <= 0 {
+		return "", nil, false
+	}
+	value, err := hex.DecodeString(strings.TrimSpace(str[pos+1:]))
+	if err != nil || len(value) == 0 {
+		return "", nil, false
+	}
+	return digestName(str[:pos]), value, true
+}
+
+// VerifyContentDigest checks data against an algorithm-tagged digest string
+//
+// Returns: false if the digest is malformed, the algorithm not supported, or not matched
+func VerifyContentDigest(str string, data []byte) bool {
+	algorithm, value, ok := ParseContentDigest(str)
+	if !ok {
+		return false
+	}
+	digester := GetDigester(algorithm)
+	if digester == nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(digester.Digest(data), value) == 1
+}
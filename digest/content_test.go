@@ -0,0 +1,92 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package digest
+
+import (
+	"testing"
+)
+
+func TestContentDigestVectors(t *testing.T) {
+	cases := []struct {
+		algorithm string
+		data      string
+		expected  string
+	}{
+		{"sha256", "abc", "sha256:ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{"SHA-256", "", "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"md5", "", "md5:d41d8cd98f00b204e9800998ecf8427e"},
+		{"sha1", "abc", "sha1:a9993e364706816aba3e25717850c26c9cd0d89d"},
+		{"sha_512", "abc", "sha512:ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+	}
+	for _, item := range cases {
+		got := ContentDigest(item.algorithm, []byte(item.data))
+		if got != item.expected {
+			t.Errorf("%s(%q): got %s, want %s", item.algorithm, item.data, got, item.expected)
+		}
+		if !VerifyContentDigest(got, []byte(item.data)) {
+			t.Errorf("failed to verify %s", got)
+		}
+		if VerifyContentDigest(got, []byte(item.data+"x")) {
+			t.Errorf("verified wrong data with %s", got)
+		}
+	}
+	if got := ContentDigest("unknown", []byte("abc")); got != "" {
+		t.Errorf("unknown algorithm: %s", got)
+	}
+}
+
+func TestParseContentDigest(t *testing.T) {
+	algorithm, value, ok := ParseContentDigest("SHA-256:00ff")
+	if !ok || algorithm != "sha256" || len(value) != 2 || value[1] != 0xFF {
+		t.Errorf("parse: %s, %x, %v", algorithm, value, ok)
+	}
+	for _, str := range []string{"", "sha256", ":00ff", "sha256:", "sha256:xyz", "sha256:0"} {
+		if _, _, ok := ParseContentDigest(str); ok {
+			t.Errorf("expected failure for %q", str)
+		}
+	}
+	if VerifyContentDigest("unknown:00ff", nil) {
+		t.Errorf("verified unknown algorithm")
+	}
+}
+
+type reverseDigester struct{}
+
+func (reverseDigester) Digest(data []byte) []byte {
+	out := make([]byte, len(data))
+	for index, b := range data {
+		out[len(data)-1-index] = b
+	}
+	return out
+}
+
+func TestSetDigester(t *testing.T) {
+	SetDigester("Reverse", reverseDigester{})
+	defer SetDigester("reverse", nil)
+	if got := ContentDigest("REVERSE", []byte("ab")); got != "reverse:6261" {
+		t.Errorf("custom digester: %s", got)
+	}
+}
@@ -48,6 +48,7 @@ var sha256Digester MessageDigester = nil
 
 func SetSHA256Digester(digester MessageDigester) {
 	sha256Digester = digester
+	SetDigester("sha256", digester)
 }
 
 func SHA256(bytes []byte) []byte {
@@ -62,6 +63,7 @@ var keccak256Digester MessageDigester = nil
 
 func SetKECCAK256Digester(digester MessageDigester) {
 	keccak256Digester = digester
+	SetDigester("keccak256", digester)
 }
 
 func KECCAK256(bytes []byte) []byte {
@@ -76,6 +78,7 @@ var ripemd160Digester MessageDigester = nil
 
 func SetRIPEMD160Digester(digester MessageDigester) {
 	ripemd160Digester = digester
+	SetDigester("ripemd160", digester)
 }
 
 func RIPEMD160(bytes []byte) []byte {
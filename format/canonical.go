@@ -0,0 +1,302 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// CanonicalJSONCoder encodes objects into canonical JSON (RFC 8785 style)
+//
+//	Encoding rules:
+//	    1. Object keys are sorted by their UTF-16 code units
+//	    2. No insignificant whitespace
+//	    3. Strings are escaped minimally (no HTML escaping)
+//	    4. Float numbers are formatted as ECMAScript does (shortest round-trip form)
+//
+// Integers are written exactly, so 64-bit values will not be rounded to doubles.
+//
+// The output does not depend on Go's map ordering, so the same properties always
+// produce the same document "data" as the Java/JS ports.
+type CanonicalJSONCoder struct {
+	//ObjectCoder
+}
+
+// Override
+func (CanonicalJSONCoder) Encode(object any) string {
+	var buf bytes.Buffer
+	err := writeCanonicalValue(&buf, object)
+	if err != nil {
+		//panic(err)
+		return ""
+	}
+	return buf.String()
+}
+
+// Override
+func (CanonicalJSONCoder) Decode(str string) any {
+	return decodeJSON(str)
+}
+
+func writeCanonicalValue(buf *bytes.Buffer, value any) error {
+	if ValueIsNil(value) {
+		buf.WriteString("null")
+		return nil
+	}
+	switch v := value.(type) {
+	// boolean
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	// string
+	case string:
+		writeCanonicalString(buf, v)
+	case json.Number:
+		return writeCanonicalNumber(buf, v)
+	// integer
+	case int:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int8:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int16:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int32:
+		buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+	// unsigned integer
+	case uint:
+		buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint8:
+		buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint16:
+		buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint32:
+		buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(v, 10))
+	// float number
+	case float32:
+		// widened to float64 first, as ECMAScript numbers are doubles
+		return writeCanonicalFloat(buf, float64(v))
+	case float64:
+		return writeCanonicalFloat(buf, v)
+	// containers
+	case StringKeyMap:
+		return writeCanonicalMap(buf, v)
+	case []any:
+		return writeCanonicalList(buf, v)
+	case Mapper:
+		return writeCanonicalMap(buf, v.Map())
+	case Stringer:
+		writeCanonicalString(buf, v.String())
+	default:
+		return writeCanonicalOther(buf, value)
+	}
+	return nil
+}
+
+// other types: reflected maps/lists, structs, ...
+func writeCanonicalOther(buf *bytes.Buffer, value any) error {
+	switch v := Unwrap(value).(type) {
+	case StringKeyMap:
+		return writeCanonicalMap(buf, v)
+	case []any:
+		return writeCanonicalList(buf, v)
+	case string:
+		writeCanonicalString(buf, v)
+		return nil
+	}
+	// let the standard encoder handle it, then normalize the result
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var object any
+	err = decoder.Decode(&object)
+	if err != nil {
+		return err
+	}
+	return writeCanonicalValue(buf, object)
+}
+
+func writeCanonicalMap(buf *bytes.Buffer, dict StringKeyMap) error {
+	keys := MapKeys(dict)
+	sort.Slice(keys, func(i, j int) bool {
+		return compareUTF16(keys[i], keys[j]) < 0
+	})
+	buf.WriteByte('{')
+	for index, key := range keys {
+		if index > 0 {
+			buf.WriteByte(',')
+		}
+		writeCanonicalString(buf, key)
+		buf.WriteByte(':')
+		err := writeCanonicalValue(buf, dict[key])
+		if err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+func writeCanonicalList(buf *bytes.Buffer, array []any) error {
+	buf.WriteByte('[')
+	for index, item := range array {
+		if index > 0 {
+			buf.WriteByte(',')
+		}
+		err := writeCanonicalValue(buf, item)
+		if err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+const lowerHex = "0123456789abcdef"
+
+func writeCanonicalString(buf *bytes.Buffer, str string) {
+	buf.WriteByte('"')
+	for index := 0; index < len(str); {
+		ch, size := utf8.DecodeRuneInString(str[index:])
+		index += size
+		switch ch {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if ch < 0x20 {
+				buf.WriteString(`\u00`)
+				buf.WriteByte(lowerHex[ch>>4])
+				buf.WriteByte(lowerHex[ch&0xF])
+			} else {
+				// invalid UTF-8 bytes are written as U+FFFD
+				buf.WriteRune(ch)
+			}
+		}
+	}
+	buf.WriteByte('"')
+}
+
+func writeCanonicalNumber(buf *bytes.Buffer, number json.Number) error {
+	if i, err := number.Int64(); err == nil {
+		buf.WriteString(strconv.FormatInt(i, 10))
+		return nil
+	}
+	str := number.String()
+	if u, err := strconv.ParseUint(str, 10, 64); err == nil {
+		buf.WriteString(strconv.FormatUint(u, 10))
+		return nil
+	}
+	f, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return err
+	}
+	return writeCanonicalFloat(buf, f)
+}
+
+func writeCanonicalFloat(buf *bytes.Buffer, f float64) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return errors.New("JSON does not support NaN or Infinity")
+	} else if f == 0 {
+		// both +0 and -0
+		buf.WriteByte('0')
+		return nil
+	} else if f < 0 {
+		buf.WriteByte('-')
+		f = -f
+	}
+	// shortest decimal digits that round-trip: "d.ddde±xx"
+	str := strconv.FormatFloat(f, 'e', -1, 64)
+	pos := strings.IndexByte(str, 'e')
+	digits := strings.Replace(str[:pos], ".", "", 1)
+	exp, _ := strconv.Atoi(str[pos+1:])
+	k := len(digits)
+	n := exp + 1
+	// ECMAScript Number::toString
+	switch {
+	case k <= n && n <= 21:
+		buf.WriteString(digits)
+		buf.WriteString(strings.Repeat("0", n-k))
+	case 0 < n && n <= 21:
+		buf.WriteString(digits[:n])
+		buf.WriteByte('.')
+		buf.WriteString(digits[n:])
+	case -6 < n && n <= 0:
+		buf.WriteString("0.")
+		buf.WriteString(strings.Repeat("0", -n))
+		buf.WriteString(digits)
+	default:
+		buf.WriteString(digits[:1])
+		if k > 1 {
+			buf.WriteByte('.')
+			buf.WriteString(digits[1:])
+		}
+		buf.WriteByte('e')
+		if n > 0 {
+			buf.WriteByte('+')
+		}
+		buf.WriteString(strconv.Itoa(n - 1))
+	}
+	return nil
+}
+
+// compare strings by UTF-16 code units (as JavaScript does)
+func compareUTF16(a, b string) int {
+	ua := utf16.Encode([]rune(a))
+	ub := utf16.Encode([]rune(b))
+	for index := 0; index < len(ua) && index < len(ub); index++ {
+		if ua[index] != ub[index] {
+			return int(ua[index]) - int(ub[index])
+		}
+	}
+	return len(ua) - len(ub)
+}
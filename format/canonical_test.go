@@ -0,0 +1,124 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"encoding/json"
+	"math"
+	"sync"
+	"testing"
+
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func TestCanonicalJSONSortedKeys(t *testing.T) {
+	coder := CanonicalJSONCoder{}
+	object := StringKeyMap{
+		"b":          1,
+		"a":          []any{true, nil, "x"},
+		"\uFB33":     "dalet",
+		"\U0001F600": "smile", // surrogate pair (U+D83D) sorts before U+FB33 by UTF-16 code units
+		"c":          StringKeyMap{"z": 1, "y": 2},
+	}
+	expected := `{"a":[true,null,"x"],"b":1,"c":{"y":2,"z":1},"` + "\U0001F600" + `":"smile","` + "\uFB33" + `":"dalet"}`
+	if got := coder.Encode(object); got != expected {
+		t.Fatalf("canonical JSON:\n got %s\nwant %s", got, expected)
+	}
+}
+
+func TestCanonicalJSONNumbers(t *testing.T) {
+	coder := CanonicalJSONCoder{}
+	cases := []struct {
+		value    any
+		expected string
+	}{
+		// RFC 8785 / ECMAScript Number::toString
+		{0.0, "0"},
+		{math.Copysign(0, -1), "0"},
+		{1.0, "1"},
+		{-1.5, "-1.5"},
+		{1e21, "1e+21"},
+		{1e20, "100000000000000000000"},
+		{1e-7, "1e-7"},
+		{0.000001, "0.000001"},
+		{4.50, "4.5"},
+		{2e-3, "0.002"},
+		{333333333.33333329, "333333333.3333333"},
+		{1e23, "1e+23"},
+		{5e-324, "5e-324"},
+		{1.7976931348623157e308, "1.7976931348623157e+308"},
+		// float32 is widened first
+		{float32(0.1), "0.10000000149011612"},
+		// integers are exact
+		{int64(9007199254740993), "9007199254740993"},
+		{uint64(18446744073709551615), "18446744073709551615"},
+		{json.Number("12345678901234567890"), "12345678901234567890"},
+		{json.Number("1.0"), "1"},
+	}
+	for _, item := range cases {
+		if got := coder.Encode(item.value); got != item.expected {
+			t.Errorf("canonical number %v: got %s, want %s", item.value, got, item.expected)
+		}
+	}
+}
+
+func TestCanonicalJSONStrings(t *testing.T) {
+	coder := CanonicalJSONCoder{}
+	got := coder.Encode("<a>\"\\\b\f\n\r\t\x01 ")
+	expected := `"<a>\"\\\b\f\n\r\t\u0001` + " " + `"`
+	if got != expected {
+		t.Fatalf("canonical string: got %s, want %s", got, expected)
+	}
+}
+
+func TestCanonicalJSONInvalid(t *testing.T) {
+	coder := CanonicalJSONCoder{}
+	for _, value := range []any{math.NaN(), math.Inf(1), StringKeyMap{"x": math.Inf(-1)}} {
+		if got := coder.Encode(value); got != "" {
+			t.Errorf("expected empty string for %v, got %s", value, got)
+		}
+	}
+}
+
+func TestCanonicalJSONConcurrent(t *testing.T) {
+	coder := CanonicalJSONCoder{}
+	object := StringKeyMap{"k3": 3, "k1": 1, "k2": StringKeyMap{"b": 2.5, "a": "x"}}
+	expected := coder.Encode(object)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if got := coder.Encode(object); got != expected {
+					t.Errorf("unstable output: %s", got)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
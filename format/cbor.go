@@ -0,0 +1,577 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// CBORCoder implements ObjectCoder with CBOR (RFC 8949)
+//
+// The serialized string holds the raw CBOR bytes.
+//
+//	Data model:
+//	    StringKeyMap   <-> map (text string keys, sorted deterministically)
+//	    []any          <-> array
+//	    string         <-> text string
+//	    []byte         <-> byte string (TransportableData is stored as raw bytes)
+//	    int64/uint64   <-> unsigned/negative integer
+//	    float64        <-> float (32 bits when exact, otherwise 64 bits)
+//	    bool, nil      <-> simple values
+//
+// Decode() returns byte strings as base64 strings (TED format 0), as JSON does,
+// so TED fields (signature, fingerprint, ...) can be parsed again;
+// use CBORDecode() to get the raw bytes.
+type CBORCoder struct {
+	//ObjectCoder
+}
+
+// Override
+func (CBORCoder) Encode(object any) string {
+	return string(CBOREncode(object))
+}
+
+// Override
+func (CBORCoder) Decode(str string) any {
+	return encodeBinaryValues(CBORDecode([]byte(str)))
+}
+
+func CBOREncode(object any) []byte {
+	var buf bytes.Buffer
+	err := writeCBORValue(&buf, object)
+	if err != nil {
+		//panic(err)
+		return nil
+	}
+	return buf.Bytes()
+}
+
+func CBORDecode(data []byte) any {
+	decoder := &cborDecoder{data: data}
+	object, err := decoder.readValue()
+	if err != nil {
+		//panic(err)
+		return nil
+	} else if decoder.pos != len(data) {
+		//panic("unexpected data after CBOR value")
+		return nil
+	}
+	return object
+}
+
+//
+//  Encoding
+//
+
+const (
+	cborUnsigned = 0 << 5
+	cborNegative = 1 << 5
+	cborBytes    = 2 << 5
+	cborText     = 3 << 5
+	cborArray    = 4 << 5
+	cborMap      = 5 << 5
+	cborTag      = 6 << 5
+	cborSimple   = 7 << 5
+
+	cborFalse   = cborSimple | 20
+	cborTrue    = cborSimple | 21
+	cborNull    = cborSimple | 22
+	cborFloat32 = cborSimple | 26
+	cborFloat64 = cborSimple | 27
+	cborBreak   = cborSimple | 31
+
+	cborIndefinite = 31
+)
+
+func writeCBORHead(buf *bytes.Buffer, major byte, n uint64) {
+	var tmp [8]byte
+	switch {
+	case n < 24:
+		buf.WriteByte(major | byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(major | 24)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(major | 25)
+		binary.BigEndian.PutUint16(tmp[:2], uint16(n))
+		buf.Write(tmp[:2])
+	case n <= math.MaxUint32:
+		buf.WriteByte(major | 26)
+		binary.BigEndian.PutUint32(tmp[:4], uint32(n))
+		buf.Write(tmp[:4])
+	default:
+		buf.WriteByte(major | 27)
+		binary.BigEndian.PutUint64(tmp[:], n)
+		buf.Write(tmp[:])
+	}
+}
+
+func writeCBORInt(buf *bytes.Buffer, i int64) {
+	if i < 0 {
+		writeCBORHead(buf, cborNegative, uint64(-1-i))
+	} else {
+		writeCBORHead(buf, cborUnsigned, uint64(i))
+	}
+}
+
+func writeCBORFloat(buf *bytes.Buffer, f float64) {
+	var tmp [8]byte
+	if f32 := float32(f); float64(f32) == f || math.IsNaN(f) {
+		buf.WriteByte(cborFloat32)
+		binary.BigEndian.PutUint32(tmp[:4], math.Float32bits(f32))
+		buf.Write(tmp[:4])
+	} else {
+		buf.WriteByte(cborFloat64)
+		binary.BigEndian.PutUint64(tmp[:], math.Float64bits(f))
+		buf.Write(tmp[:])
+	}
+}
+
+func writeCBORText(buf *bytes.Buffer, str string) {
+	if !utf8.ValidString(str) {
+		str = strings.ToValidUTF8(str, "\uFFFD")
+	}
+	writeCBORHead(buf, cborText, uint64(len(str)))
+	buf.WriteString(str)
+}
+
+func writeCBORValue(buf *bytes.Buffer, value any) error {
+	if ValueIsNil(value) {
+		buf.WriteByte(cborNull)
+		return nil
+	}
+	switch v := value.(type) {
+	// boolean
+	case bool:
+		if v {
+			buf.WriteByte(cborTrue)
+		} else {
+			buf.WriteByte(cborFalse)
+		}
+	// string
+	case string:
+		writeCBORText(buf, v)
+	// binary data
+	case []byte:
+		writeCBORHead(buf, cborBytes, uint64(len(v)))
+		buf.Write(v)
+	// integer
+	case int:
+		writeCBORInt(buf, int64(v))
+	case int8:
+		writeCBORInt(buf, int64(v))
+	case int16:
+		writeCBORInt(buf, int64(v))
+	case int32:
+		writeCBORInt(buf, int64(v))
+	case int64:
+		writeCBORInt(buf, v)
+	// unsigned integer
+	case uint:
+		writeCBORHead(buf, cborUnsigned, uint64(v))
+	case uint8:
+		writeCBORHead(buf, cborUnsigned, uint64(v))
+	case uint16:
+		writeCBORHead(buf, cborUnsigned, uint64(v))
+	case uint32:
+		writeCBORHead(buf, cborUnsigned, uint64(v))
+	case uint64:
+		writeCBORHead(buf, cborUnsigned, v)
+	// float number
+	case float32:
+		writeCBORFloat(buf, float64(v))
+	case float64:
+		writeCBORFloat(buf, v)
+	// containers
+	case StringKeyMap:
+		return writeCBORMap(buf, v)
+	case []any:
+		writeCBORHead(buf, cborArray, uint64(len(v)))
+		for _, item := range v {
+			err := writeCBORValue(buf, item)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		object, err := normalizeObject(value)
+		if err != nil {
+			return err
+		}
+		return writeCBORValue(buf, object)
+	}
+	return nil
+}
+
+func writeCBORMap(buf *bytes.Buffer, dict StringKeyMap) error {
+	// deterministic order: bytewise lexicographic order of the encoded keys
+	type entry struct {
+		key   []byte
+		value any
+	}
+	entries := make([]entry, 0, len(dict))
+	for key, value := range dict {
+		var kb bytes.Buffer
+		writeCBORText(&kb, key)
+		entries = append(entries, entry{key: kb.Bytes(), value: value})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+	writeCBORHead(buf, cborMap, uint64(len(entries)))
+	for _, item := range entries {
+		buf.Write(item.key)
+		err := writeCBORValue(buf, item.value)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//
+//  Decoding
+//
+
+const cborMaxDepth = 512
+
+var errCBORTruncated = errors.New("CBOR data truncated")
+
+type cborDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (decoder *cborDecoder) readByte() (byte, error) {
+	if decoder.pos >= len(decoder.data) {
+		return 0, errCBORTruncated
+	}
+	b := decoder.data[decoder.pos]
+	decoder.pos++
+	return b, nil
+}
+
+func (decoder *cborDecoder) readBytes(n uint64) ([]byte, error) {
+	if n > uint64(len(decoder.data)-decoder.pos) {
+		return nil, errCBORTruncated
+	}
+	start := decoder.pos
+	decoder.pos += int(n)
+	return decoder.data[start:decoder.pos], nil
+}
+
+// readArgument reads the argument of a head with additional info
+func (decoder *cborDecoder) readArgument(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		b, err := decoder.readByte()
+		return uint64(b), err
+	case info == 25:
+		b, err := decoder.readBytes(2)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case info == 26:
+		b, err := decoder.readBytes(4)
+		if err != nil {
+			return 0, err
+		}
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	case info == 27:
+		b, err := decoder.readBytes(8)
+		if err != nil {
+			return 0, err
+		}
+		return binary.BigEndian.Uint64(b), nil
+	default:
+		return 0, errors.New("CBOR additional info error")
+	}
+}
+
+// checkCount prevents allocating for counts larger than the remaining data
+func (decoder *cborDecoder) checkCount(count uint64) error {
+	if count > uint64(len(decoder.data)-decoder.pos) {
+		return errCBORTruncated
+	}
+	return nil
+}
+
+func (decoder *cborDecoder) readValue() (any, error) {
+	head, err := decoder.readByte()
+	if err != nil {
+		return nil, err
+	}
+	return decoder.readItem(head)
+}
+
+func (decoder *cborDecoder) readItem(head byte) (any, error) {
+	major := head & 0xE0
+	info := head & 0x1F
+	if major == cborSimple {
+		return decoder.readSimple(info)
+	} else if info == cborIndefinite {
+		return decoder.readIndefinite(major)
+	}
+	arg, err := decoder.readArgument(info)
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case cborUnsigned:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+	case cborNegative:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("CBOR negative integer overflow")
+		}
+		return -1 - int64(arg), nil
+	case cborBytes:
+		b, err := decoder.readBytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte{}, b...), nil
+	case cborText:
+		b, err := decoder.readBytes(arg)
+		if err != nil {
+			return nil, err
+		}
+		return string(b), nil
+	case cborArray:
+		return decoder.readArray(arg)
+	case cborMap:
+		return decoder.readMap(arg)
+	default: // cborTag
+		// tags are not part of the object model, return the tagged content
+		return decoder.readNested()
+	}
+}
+
+func (decoder *cborDecoder) readNested() (any, error) {
+	decoder.depth++
+	if decoder.depth > cborMaxDepth {
+		return nil, errors.New("CBOR nesting too deep")
+	}
+	value, err := decoder.readValue()
+	decoder.depth--
+	return value, err
+}
+
+func (decoder *cborDecoder) readArray(count uint64) ([]any, error) {
+	err := decoder.checkCount(count)
+	if err != nil {
+		return nil, err
+	}
+	array := make([]any, count)
+	for index := range array {
+		array[index], err = decoder.readNested()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return array, nil
+}
+
+func (decoder *cborDecoder) readMap(count uint64) (StringKeyMap, error) {
+	err := decoder.checkCount(count)
+	if err != nil {
+		return nil, err
+	}
+	dict := make(StringKeyMap, count)
+	for ; count > 0; count-- {
+		err = decoder.readEntry(dict)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dict, nil
+}
+
+func (decoder *cborDecoder) readEntry(dict StringKeyMap) error {
+	key, err := decoder.readNested()
+	if err != nil {
+		return err
+	}
+	text, ok := key.(string)
+	if !ok {
+		return errors.New("CBOR map key is not a text string")
+	}
+	dict[text], err = decoder.readNested()
+	return err
+}
+
+// isBreak checks and skips the "break" stop code
+func (decoder *cborDecoder) isBreak() (bool, error) {
+	if decoder.pos >= len(decoder.data) {
+		return false, errCBORTruncated
+	} else if decoder.data[decoder.pos] != cborBreak {
+		return false, nil
+	}
+	decoder.pos++
+	return true, nil
+}
+
+func (decoder *cborDecoder) readIndefinite(major byte) (any, error) {
+	switch major {
+	case cborBytes, cborText:
+		// concatenate definite-length chunks of the same major type
+		var buf bytes.Buffer
+		for {
+			stop, err := decoder.isBreak()
+			if err != nil {
+				return nil, err
+			} else if stop {
+				break
+			}
+			head, _ := decoder.readByte()
+			if head&0xE0 != major || head&0x1F == cborIndefinite {
+				return nil, errors.New("CBOR indefinite string chunk error")
+			}
+			size, err := decoder.readArgument(head & 0x1F)
+			if err != nil {
+				return nil, err
+			}
+			chunk, err := decoder.readBytes(size)
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(chunk)
+		}
+		if major == cborText {
+			return buf.String(), nil
+		}
+		return buf.Bytes(), nil
+	case cborArray:
+		array := make([]any, 0)
+		for {
+			stop, err := decoder.isBreak()
+			if err != nil {
+				return nil, err
+			} else if stop {
+				return array, nil
+			}
+			item, err := decoder.readNested()
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, item)
+		}
+	case cborMap:
+		dict := NewMap()
+		for {
+			stop, err := decoder.isBreak()
+			if err != nil {
+				return nil, err
+			} else if stop {
+				return dict, nil
+			}
+			err = decoder.readEntry(dict)
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, errors.New("CBOR indefinite length not allowed")
+	}
+}
+
+func (decoder *cborDecoder) readSimple(info byte) (any, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23: // null, undefined
+		return nil, nil
+	case 24:
+		// other simple values are not part of the object model
+		_, err := decoder.readByte()
+		return nil, err
+	case 25:
+		b, err := decoder.readBytes(2)
+		if err != nil {
+			return nil, err
+		}
+		return halfToFloat64(binary.BigEndian.Uint16(b)), nil
+	case 26:
+		b, err := decoder.readBytes(4)
+		if err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), nil
+	case 27:
+		b, err := decoder.readBytes(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), nil
+	case cborIndefinite:
+		return nil, errors.New("unexpected CBOR break")
+	default:
+		if info < 20 {
+			// unassigned simple values
+			return nil, nil
+		}
+		return nil, errors.New("CBOR simple value error")
+	}
+}
+
+// IEEE 754 half-precision
+func halfToFloat64(half uint16) float64 {
+	exp := int(half>>10) & 0x1F
+	mant := float64(half & 0x3FF)
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 0x1F:
+		if mant == 0 {
+			f = math.Inf(1)
+		} else {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+	if half&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
@@ -0,0 +1,226 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"testing"
+
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func mustHex(t *testing.T, str string) []byte {
+	t.Helper()
+	data, err := hex.DecodeString(str)
+	if err != nil {
+		t.Fatalf("bad hex %q: %v", str, err)
+	}
+	return data
+}
+
+// RFC 8949, Appendix A
+func TestCBOREncodeVectors(t *testing.T) {
+	cases := []struct {
+		value    any
+		expected string
+	}{
+		{0, "00"},
+		{1, "01"},
+		{10, "0a"},
+		{23, "17"},
+		{24, "1818"},
+		{25, "1819"},
+		{100, "1864"},
+		{1000, "1903e8"},
+		{1000000, "1a000f4240"},
+		{int64(1000000000000), "1b000000e8d4a51000"},
+		{uint64(18446744073709551615), "1bffffffffffffffff"},
+		{-1, "20"},
+		{-10, "29"},
+		{-100, "3863"},
+		{-1000, "3903e7"},
+		{int64(math.MinInt64), "3b7fffffffffffffff"},
+		{100000.0, "fa47c35000"},
+		{3.4028234663852886e+38, "fa7f7fffff"},
+		{1.1, "fb3ff199999999999a"},
+		{1.0e+300, "fb7e37e43c8800759c"},
+		{-4.1, "fbc010666666666666"},
+		{false, "f4"},
+		{true, "f5"},
+		{nil, "f6"},
+		{[]byte{}, "40"},
+		{[]byte{1, 2, 3, 4}, "4401020304"},
+		{"", "60"},
+		{"a", "6161"},
+		{"IETF", "6449455446"},
+		{"\"\\", "62225c"},
+		{"ü", "62c3bc"},
+		{"水", "63e6b0b4"},
+		{"\U00010151", "64f0908591"},
+		{[]any{}, "80"},
+		{[]any{1, 2, 3}, "83010203"},
+		{[]any{1, []any{2, 3}, []any{4, 5}}, "8301820203820405"},
+		{StringKeyMap{}, "a0"},
+		{StringKeyMap{"a": 1, "b": []any{2, 3}}, "a26161016162820203"},
+		{[]any{"a", StringKeyMap{"b": "c"}}, "826161a161626163"},
+		{StringKeyMap{"a": "A", "b": "B", "c": "C", "d": "D", "e": "E"}, "a56161614161626142616361436164614461656145"},
+	}
+	for _, item := range cases {
+		got := hex.EncodeToString(CBOREncode(item.value))
+		if got != item.expected {
+			t.Errorf("CBOR encode %#v: got %s, want %s", item.value, got, item.expected)
+		}
+	}
+}
+
+// RFC 8949, Appendix A
+func TestCBORDecodeVectors(t *testing.T) {
+	cases := []struct {
+		data     string
+		expected any
+	}{
+		{"00", int64(0)},
+		{"1903e8", int64(1000)},
+		{"1bffffffffffffffff", uint64(18446744073709551615)},
+		{"3903e7", int64(-1000)},
+		{"f90000", 0.0},
+		{"f93c00", 1.0},
+		{"f93e00", 1.5},
+		{"f97bff", 65504.0},
+		{"f90001", 5.960464477539063e-8},
+		{"f90400", 0.00006103515625},
+		{"f9c400", -4.0},
+		{"f97c00", math.Inf(1)},
+		{"fa47c35000", 100000.0},
+		{"fb3ff199999999999a", 1.1},
+		{"f4", false},
+		{"f5", true},
+		{"f6", nil},
+		{"c074323031332d30332d32315432303a30343a30305a", "2013-03-21T20:04:00Z"},
+		{"d74401020304", []byte{1, 2, 3, 4}},
+		{"5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+		{"7f657374726561646d696e67ff", "streaming"},
+		{"9fff", []any{}},
+		{"9f018202039f0405ffff", []any{int64(1), []any{int64(2), int64(3)}, []any{int64(4), int64(5)}}},
+		{"bf61610161629f0203ffff", StringKeyMap{"a": int64(1), "b": []any{int64(2), int64(3)}}},
+		{"bf6346756ef563416d7421ff", StringKeyMap{"Fun": true, "Amt": int64(-2)}},
+	}
+	for _, item := range cases {
+		got := CBORDecode(mustHex(t, item.data))
+		if !reflect.DeepEqual(got, item.expected) {
+			t.Errorf("CBOR decode %s: got %#v, want %#v", item.data, got, item.expected)
+		}
+	}
+	if got := CBORDecode(mustHex(t, "f97e00")); !math.IsNaN(got.(float64)) {
+		t.Errorf("CBOR decode NaN: got %v", got)
+	}
+}
+
+func TestCBORMalformed(t *testing.T) {
+	cases := []string{
+		"",                   // empty
+		"18",                 // missing argument
+		"62c3",               // truncated text
+		"8301",               // truncated array
+		"a16161",             // missing map value
+		"a10102",             // non-text key
+		"0000",               // trailing data
+		"5f6161ff",           // wrong chunk type
+		"9b00000000ffffffff", // array too large
+	}
+	for _, item := range cases {
+		if got := CBORDecode(mustHex(t, item)); got != nil {
+			t.Errorf("CBOR decode %q: expected nil, got %#v", item, got)
+		}
+	}
+	// nesting too deep
+	deep := bytes.Repeat([]byte{0x81}, 1000)
+	deep = append(deep, 0x00)
+	if got := CBORDecode(deep); got != nil {
+		t.Errorf("CBOR deep nesting: expected nil")
+	}
+}
+
+func TestCBORRoundTrip(t *testing.T) {
+	object := StringKeyMap{
+		"did":    "moky@4DnqXWdTV8wuZgfqSCX9GjE2kNq7HJrUgQ",
+		"time":   int64(1700000000123),
+		"big":    uint64(math.MaxUint64),
+		"neg":    int64(-123456789),
+		"pi":     3.141592653589793,
+		"half":   0.5,
+		"ok":     true,
+		"none":   nil,
+		"data":   []byte{0, 1, 2, 0xFF},
+		"list":   []any{"a", int64(1), []any{}, StringKeyMap{}},
+		"nested": StringKeyMap{"x": StringKeyMap{"y": "z"}},
+	}
+	got := CBORDecode(CBOREncode(object))
+	if !reflect.DeepEqual(got, object) {
+		t.Fatalf("CBOR round trip:\n got %#v\nwant %#v", got, object)
+	}
+	// object coder returns binary data as base64 string
+	coder := CBORCoder{}
+	got = coder.Decode(coder.Encode(object))
+	object["data"] = "AAEC/w=="
+	if !reflect.DeepEqual(got, object) {
+		t.Fatalf("CBOR coder round trip:\n got %#v\nwant %#v", got, object)
+	}
+	// deterministic output
+	if a, b := coder.Encode(object), coder.Encode(got); a != b {
+		t.Fatalf("CBOR output not deterministic")
+	}
+}
+
+func TestCBORTransportableData(t *testing.T) {
+	signature := NewEncodedData([]byte{0, 1, 2, 0xFF}, nil)
+	avatar := NewEncodedData([]byte("PNG"), NewDataHeader("image/png", "base64"))
+	object := StringKeyMap{
+		"signature": signature,
+		"key":       StringKeyMap{"fingerprint": signature},
+		"avatars":   []any{avatar},
+	}
+	coder := CBORCoder{}
+	got := FetchMap(coder.Decode(coder.Encode(object)))
+	if got == nil {
+		t.Fatalf("CBOR failed to decode TED object")
+	}
+	factory := EncodedDataFactory{}
+	fingerprint := FetchMap(got["key"])["fingerprint"]
+	for _, value := range []any{got["signature"], fingerprint} {
+		ted := factory.ParseTransportableData(FetchString(value))
+		if ted == nil || !bytes.Equal(ted.Bytes(), signature.Bytes()) {
+			t.Errorf("CBOR TED not restored: %#v", value)
+		}
+	}
+	ted := factory.ParseTransportableData(FetchString(FetchList(got["avatars"])[0]))
+	if ted == nil || ted.MimeType() != "image/png" || string(ted.Bytes()) != "PNG" {
+		t.Errorf("CBOR data URI not restored: %#v", got["avatars"])
+	}
+}
@@ -0,0 +1,291 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"encoding/binary"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+//
+//  UTF-8
+//
+
+// UTF8Coder is the default implementation of StringCoder
+//
+// Invalid byte sequences are replaced with U+FFFD in both directions,
+// so the result is always valid UTF-8.
+type UTF8Coder struct {
+	//StringCoder
+}
+
+// Override
+func (UTF8Coder) Encode(str string) []byte {
+	if !utf8.ValidString(str) {
+		str = strings.ToValidUTF8(str, "\uFFFD")
+	}
+	return []byte(str)
+}
+
+// Override
+func (UTF8Coder) Decode(bytes []byte) string {
+	if !utf8.Valid(bytes) {
+		return strings.ToValidUTF8(string(bytes), "\uFFFD")
+	}
+	return string(bytes)
+}
+
+//
+//  UTF-16
+//
+
+// UTF16Coder implements StringCoder for UTF-16
+//
+// When BOM is set, the encoder writes a byte order mark,
+// and the decoder detects the byte order from it (defaults to ByteOrder).
+type UTF16Coder struct {
+	//StringCoder
+
+	ByteOrder binary.ByteOrder
+	BOM       bool
+}
+
+// Override
+func (coder UTF16Coder) Encode(str string) []byte {
+	units := utf16.Encode([]rune(str))
+	offset := 0
+	if coder.BOM {
+		offset = 2
+	}
+	bytes := make([]byte, offset+len(units)*2)
+	if coder.BOM {
+		coder.ByteOrder.PutUint16(bytes, 0xFEFF)
+	}
+	for index, unit := range units {
+		coder.ByteOrder.PutUint16(bytes[offset+index*2:], unit)
+	}
+	return bytes
+}
+
+// Override
+func (coder UTF16Coder) Decode(bytes []byte) string {
+	order := coder.ByteOrder
+	if coder.BOM && len(bytes) >= 2 {
+		if bytes[0] == 0xFE && bytes[1] == 0xFF {
+			order = binary.BigEndian
+			bytes = bytes[2:]
+		} else if bytes[0] == 0xFF && bytes[1] == 0xFE {
+			order = binary.LittleEndian
+			bytes = bytes[2:]
+		}
+	}
+	units := make([]uint16, len(bytes)/2)
+	for index := range units {
+		units[index] = order.Uint16(bytes[index*2:])
+	}
+	str := string(utf16.Decode(units))
+	if len(bytes)%2 != 0 {
+		// incomplete code unit
+		str += "\uFFFD"
+	}
+	return str
+}
+
+//
+//  GBK / GB18030
+//
+
+// GB18030Coder implements StringCoder for the Chinese national charsets
+//
+// The decoder accepts GB2312, GBK and GB18030 (1, 2 and 4 bytes sequences).
+// When GBK is set, the encoder only writes single/double bytes sequences
+// (as CP936 does, U+20AC is written as 0x80), other characters become '?'.
+type GB18030Coder struct {
+	//StringCoder
+
+	GBK bool
+}
+
+var (
+	gb18030Reverse     map[rune]uint16
+	gb18030ReverseOnce sync.Once
+)
+
+func gb18030Lookup(ch rune) (uint16, bool) {
+	gb18030ReverseOnce.Do(func() {
+		gb18030Reverse = make(map[rune]uint16, len(gb18030Index))
+		for index, code := range gb18030Index {
+			ch := rune(code)
+			if _, exists := gb18030Reverse[ch]; !exists {
+				gb18030Reverse[ch] = uint16(index)
+			}
+		}
+	})
+	index, ok := gb18030Reverse[ch]
+	return index, ok
+}
+
+// Override
+func (coder GB18030Coder) Encode(str string) []byte {
+	bytes := make([]byte, 0, len(str))
+	for _, ch := range str {
+		if ch < 0x80 {
+			bytes = append(bytes, byte(ch))
+			continue
+		} else if ch == 0x20AC && coder.GBK {
+			bytes = append(bytes, 0x80)
+			continue
+		} else if index, ok := gb18030Lookup(ch); ok {
+			lead := index / 190
+			trail := index % 190
+			if trail < 0x3F {
+				trail += 0x40
+			} else {
+				trail += 0x41
+			}
+			bytes = append(bytes, byte(lead+0x81), byte(trail))
+			continue
+		} else if coder.GBK {
+			bytes = append(bytes, '?')
+			continue
+		}
+		pointer, ok := gb18030Pointer(ch)
+		if !ok {
+			bytes = append(bytes, '?')
+			continue
+		}
+		b4 := pointer % 10
+		pointer /= 10
+		b3 := pointer % 126
+		pointer /= 126
+		b2 := pointer % 10
+		b1 := pointer / 10
+		bytes = append(bytes, byte(b1+0x81), byte(b2+0x30), byte(b3+0x81), byte(b4+0x30))
+	}
+	return bytes
+}
+
+// Override
+func (coder GB18030Coder) Decode(bytes []byte) string {
+	var sb strings.Builder
+	sb.Grow(len(bytes))
+	for pos := 0; pos < len(bytes); {
+		b1 := bytes[pos]
+		if b1 < 0x80 {
+			sb.WriteByte(b1)
+			pos++
+			continue
+		} else if b1 == 0x80 {
+			sb.WriteRune(0x20AC)
+			pos++
+			continue
+		} else if b1 == 0xFF || pos+1 >= len(bytes) {
+			sb.WriteRune(utf8.RuneError)
+			pos++
+			continue
+		}
+		b2 := bytes[pos+1]
+		// four-byte sequence
+		if 0x30 <= b2 && b2 <= 0x39 {
+			if pos+3 < len(bytes) {
+				b3 := bytes[pos+2]
+				b4 := bytes[pos+3]
+				if 0x81 <= b3 && b3 <= 0xFE && 0x30 <= b4 && b4 <= 0x39 {
+					pointer := (((uint32(b1)-0x81)*10+(uint32(b2)-0x30))*126+(uint32(b3)-0x81))*10 + (uint32(b4) - 0x30)
+					sb.WriteRune(gb18030Rune(pointer))
+					pos += 4
+					continue
+				}
+			}
+			sb.WriteRune(utf8.RuneError)
+			pos++
+			continue
+		}
+		// two-byte sequence
+		var ch rune = utf8.RuneError
+		if 0x40 <= b2 && b2 <= 0xFE && b2 != 0x7F {
+			index := (int(b1)-0x81)*190 + int(b2) - 0x40
+			if b2 > 0x7F {
+				index--
+			}
+			if code := gb18030Index[index]; code != 0 {
+				ch = rune(code)
+			}
+		}
+		sb.WriteRune(ch)
+		if ch == utf8.RuneError && b2 < 0x80 {
+			// do not swallow the ASCII byte
+			pos++
+		} else {
+			pos += 2
+		}
+	}
+	return sb.String()
+}
+
+// supplementary planes start from 0x90308130
+const gb18030LinearSupplementary = 189000
+
+// gb18030Rune converts a four-byte pointer to code point
+func gb18030Rune(pointer uint32) rune {
+	if pointer >= gb18030LinearSupplementary {
+		ch := rune(pointer-gb18030LinearSupplementary) + 0x10000
+		if ch > utf8.MaxRune {
+			return utf8.RuneError
+		}
+		return ch
+	} else if pointer >= gb18030LinearBMP {
+		return utf8.RuneError
+	}
+	// last run starts not after the pointer
+	index := sort.Search(len(gb18030Ranges), func(i int) bool {
+		return gb18030Ranges[i][0] > pointer
+	}) - 1
+	run := gb18030Ranges[index]
+	return rune(run[1] + pointer - run[0])
+}
+
+// gb18030Pointer converts a code point (not in two-byte index) to four-byte pointer
+func gb18030Pointer(ch rune) (uint32, bool) {
+	if ch >= 0x10000 {
+		return uint32(ch-0x10000) + gb18030LinearSupplementary, ch <= utf8.MaxRune
+	} else if 0xD800 <= ch && ch <= 0xDFFF {
+		// surrogates
+		return 0, false
+	}
+	index := sort.Search(len(gb18030Ranges), func(i int) bool {
+		return rune(gb18030Ranges[i][1]) > ch
+	}) - 1
+	if index < 0 {
+		return 0, false
+	}
+	run := gb18030Ranges[index]
+	return run[0] + uint32(ch) - run[1], true
+}
@@ -0,0 +1,152 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+)
+
+func TestUTF8Invalid(t *testing.T) {
+	coder := UTF8Coder{}
+	if got := coder.Decode([]byte{'a', 0xFF, 'b'}); got != "a�b" {
+		t.Errorf("UTF-8 decode invalid: %q", got)
+	}
+	if got := coder.Encode("a\xC0b"); string(got) != "a�b" {
+		t.Errorf("UTF-8 encode invalid: %q", got)
+	}
+}
+
+func TestUTF16(t *testing.T) {
+	str := "A\U0001F600"
+	cases := []struct {
+		coder    UTF16Coder
+		expected string
+	}{
+		{UTF16Coder{ByteOrder: binary.BigEndian, BOM: true}, "feff0041d83dde00"},
+		{UTF16Coder{ByteOrder: binary.BigEndian}, "0041d83dde00"},
+		{UTF16Coder{ByteOrder: binary.LittleEndian}, "41003dd800de"},
+	}
+	for _, item := range cases {
+		data := item.coder.Encode(str)
+		if got := hex.EncodeToString(data); got != item.expected {
+			t.Errorf("UTF-16 encode: got %s, want %s", got, item.expected)
+		}
+		if got := item.coder.Decode(data); got != str {
+			t.Errorf("UTF-16 decode: got %q, want %q", got, str)
+		}
+	}
+	// BOM decides the byte order
+	coder := UTF16Coder{ByteOrder: binary.BigEndian, BOM: true}
+	if got := coder.Decode(mustHex(t, "fffe41003dd800de")); got != str {
+		t.Errorf("UTF-16LE with BOM: got %q", got)
+	}
+	// odd length
+	if got := coder.Decode([]byte{0, 'A', 0}); got != "A�" {
+		t.Errorf("UTF-16 odd length: got %q", got)
+	}
+}
+
+func TestGB18030Vectors(t *testing.T) {
+	cases := []struct {
+		str      string
+		expected string
+	}{
+		{"abc", "616263"},
+		{"中文", "d6d0cec4"},
+		{"　", "a1a1"},
+		{"·", "a1a4"},
+		{"€", "a2e3"},
+		{"\u0080", "81308130"},
+		{"¥", "81308436"},
+		{"￿", "8431a439"},
+		{"\U00010000", "90308130"},
+		{"\U0010FFFF", "e3329a35"},
+	}
+	coder := GB18030Coder{}
+	for _, item := range cases {
+		data := coder.Encode(item.str)
+		if got := hex.EncodeToString(data); got != item.expected {
+			t.Errorf("GB18030 encode %q: got %s, want %s", item.str, got, item.expected)
+		}
+		if got := coder.Decode(data); got != item.str {
+			t.Errorf("GB18030 decode %s: got %q, want %q", item.expected, got, item.str)
+		}
+	}
+}
+
+func TestGBK(t *testing.T) {
+	coder := GB18030Coder{GBK: true}
+	if got := hex.EncodeToString(coder.Encode("中€\U0001F600")); got != "d6d0803f" {
+		t.Errorf("GBK encode: got %s", got)
+	}
+	if got := coder.Decode(mustHex(t, "d6d080")); got != "中€" {
+		t.Errorf("GBK decode: got %q", got)
+	}
+}
+
+func TestGB18030Malformed(t *testing.T) {
+	coder := GB18030Coder{}
+	cases := []struct {
+		data     string
+		expected string
+	}{
+		{"d6", "�"},                 // truncated
+		{"ff41", "�A"},              // invalid lead byte
+		{"8141", "丄"},               // two-byte code
+		{"8130", "�0"},              // truncated four-byte sequence
+		{"81308130d6d0", "\u0080中"}, // four-byte then two-byte
+	}
+	for _, item := range cases {
+		if got := coder.Decode(mustHex(t, item.data)); got != item.expected {
+			t.Errorf("GB18030 decode %s: got %q, want %q", item.data, got, item.expected)
+		}
+	}
+}
+
+func TestGB18030RoundTrip(t *testing.T) {
+	coder := GB18030Coder{}
+	var runes []rune
+	for ch := rune(0); ch <= 0x10FFFF; ch += 7 {
+		if 0xD800 <= ch && ch <= 0xDFFF {
+			continue
+		}
+		runes = append(runes, ch)
+	}
+	str := string(runes)
+	if got := coder.Decode(coder.Encode(str)); got != str {
+		t.Fatalf("GB18030 round trip failed")
+	}
+}
+
+func TestStringCoderNames(t *testing.T) {
+	for _, name := range []string{"UTF-8", "utf_16LE", "GBK", "GB2312", "gb18030"} {
+		if GetStringCoder(name) == nil {
+			t.Errorf("string coder not found: %s", name)
+		}
+	}
+}
@@ -56,6 +56,7 @@ var base64Coder DataCoder = nil
 
 func SetBase64Coder(coder DataCoder) {
 	base64Coder = coder
+	SetDataCoder("base64", coder)
 }
 
 func Base64Encode(bytes []byte) string {
@@ -74,6 +75,7 @@ var base58Coder DataCoder = nil
 
 func SetBase58Coder(coder DataCoder) {
 	base58Coder = coder
+	SetDataCoder("base58", coder)
 }
 
 func Base58Encode(bytes []byte) string {
@@ -92,6 +94,7 @@ var hexCoder DataCoder = nil
 
 func SetHexCoder(coder DataCoder) {
 	hexCoder = coder
+	SetDataCoder("hex", coder)
 }
 
 func HexEncode(bytes []byte) string {
@@ -101,3 +104,21 @@ func HexEncode(bytes []byte) string {
 func HexDecode(h string) []byte {
 	return hexCoder.Decode(h)
 }
+
+//
+//  Data Coders
+//
+
+var dataCoders = map[string]DataCoder{}
+
+// SetDataCoder registers a data coder with encoding name
+//
+// Names are case-insensitive, e.g. "base64", "BASE58", "hex", ...
+func SetDataCoder(encoding string, coder DataCoder) {
+	dataCoders[coderName(encoding)] = coder
+}
+
+// GetDataCoder returns the data coder registered with encoding name (nil if not found)
+func GetDataCoder(encoding string) DataCoder {
+	return dataCoders[coderName(encoding)]
+}
@@ -0,0 +1,197 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// EncodedData is a TransportableData in plain or data URI form
+//
+//	Formats:
+//	    0. "{BASE64_ENCODE}"
+//	    1. "data:image/png;filename=avatar.png;base64,{BASE64_ENCODE}"
+//
+// Data coders are looked up by the encoding name in the header,
+// so "base58", "hex" or other registered encodings can be used as well.
+type EncodedData struct {
+	//TransportableData
+
+	header  *DataHeader // nil for plain base64 string
+	encoded string      // encoded data part
+	data    []byte      // decoded binary data
+}
+
+// NewEncodedData creates TED with binary data
+//
+// Parameters:
+//   - data: Raw binary data
+//   - header: Data URI header (nil for plain base64 string), copied
+func NewEncodedData(data []byte, header *DataHeader) *EncodedData {
+	return &EncodedData{
+		header: header.Clone(),
+		data:   data,
+	}
+}
+
+// ParseEncodedData parses TED string in format 0 or 1
+func ParseEncodedData(ted string) *EncodedData {
+	header, body := ParseDataURI(ted)
+	if header == nil {
+		// plain base64 string
+		return &EncodedData{
+			encoded: ted,
+		}
+	}
+	return &EncodedData{
+		header:  header,
+		encoded: body,
+	}
+}
+
+//-------- IObject
+
+// Override
+func (ted *EncodedData) Equal(other any) bool {
+	if other == nil {
+		return ted.IsEmpty()
+	}
+	switch v := other.(type) {
+	case TransportableData:
+		if v == TransportableData(ted) {
+			return true
+		}
+		return bytes.Equal(ted.Bytes(), v.Bytes())
+	case fmt.Stringer:
+		return ted.String() == v.String()
+	case string:
+		return ted.String() == v
+	default:
+		// type not matched
+		return false
+	}
+}
+
+//-------- Stringer
+
+// Override
+func (ted *EncodedData) IsEmpty() bool {
+	return ted.Size() == 0
+}
+
+// Override
+func (ted *EncodedData) String() string {
+	body := ted.encodedBody()
+	if ted.header == nil {
+		return body
+	}
+	return ted.header.String() + body
+}
+
+//-------- TransportableResource
+
+// Override
+func (ted *EncodedData) Serialize() any {
+	return ted.String()
+}
+
+//-------- TransportableData
+
+// Override
+func (ted *EncodedData) Encoding() string {
+	if ted.header == nil {
+		return "base64"
+	}
+	return ted.header.Encoding()
+}
+
+// Override
+func (ted *EncodedData) MimeType() string {
+	if ted.header == nil {
+		return ""
+	}
+	return ted.header.MimeType()
+}
+
+// Override
+func (ted *EncodedData) Filename() string {
+	if ted.header == nil {
+		return ""
+	}
+	return ted.header.Filename()
+}
+
+// Header returns a copy of the data URI header,
+// changing it does not affect the encoded data
+func (ted *EncodedData) Header() *DataHeader {
+	return ted.header.Clone()
+}
+
+// Override
+func (ted *EncodedData) Bytes() []byte {
+	if ted.data == nil && ted.encoded != "" {
+		if ted.header == nil {
+			ted.data = Base64Decode(ted.encoded)
+		} else {
+			ted.data = DecodeDataURIBody(ted.header, ted.encoded)
+		}
+	}
+	return ted.data
+}
+
+// Override
+func (ted *EncodedData) Size() int {
+	return len(ted.Bytes())
+}
+
+func (ted *EncodedData) encodedBody() string {
+	if ted.encoded == "" && len(ted.data) > 0 {
+		if ted.header == nil {
+			ted.encoded = Base64Encode(ted.data)
+		} else if body, ok := EncodeDataURIBody(ted.header, ted.data); ok {
+			ted.encoded = body
+		}
+	}
+	return ted.encoded
+}
+
+/**
+ *  TED Factory
+ */
+
+// EncodedDataFactory creates EncodedData for TED strings
+type EncodedDataFactory struct {
+	//TransportableDataFactory
+}
+
+// Override
+func (EncodedDataFactory) ParseTransportableData(ted string) TransportableData {
+	if ted == "" {
+		return nil
+	}
+	return ParseEncodedData(ted)
+}
@@ -0,0 +1,104 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// JSONCoder is the default JSON implementation of ObjectCoder
+//
+// Numbers are decoded without losing precision:
+//   - integral numbers become int64 (or uint64 when larger than MaxInt64)
+//   - other numbers are kept as json.Number
+//
+// so 64-bit timestamps and sequence numbers survive a round trip through a Dictionary.
+type JSONCoder struct {
+	//ObjectCoder
+}
+
+// Override
+func (JSONCoder) Encode(object any) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(Unwrap(object))
+	if err != nil {
+		//panic(err)
+		return ""
+	}
+	// remove the newline appended by the encoder
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// Override
+func (JSONCoder) Decode(str string) any {
+	return decodeJSON(str)
+}
+
+func decodeJSON(str string) any {
+	decoder := json.NewDecoder(strings.NewReader(str))
+	decoder.UseNumber()
+	var object any
+	err := decoder.Decode(&object)
+	if err != nil {
+		//panic(err)
+		return nil
+	}
+	return convertJSONNumbers(object)
+}
+
+// convert json.Number values recursively
+func convertJSONNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		return convertJSONNumber(v)
+	case StringKeyMap:
+		for key, item := range v {
+			v[key] = convertJSONNumbers(item)
+		}
+	case []any:
+		for index, item := range v {
+			v[index] = convertJSONNumbers(item)
+		}
+	}
+	return value
+}
+
+func convertJSONNumber(number json.Number) any {
+	if i, err := number.Int64(); err == nil {
+		return i
+	} else if u, err := strconv.ParseUint(number.String(), 10, 64); err == nil {
+		return u
+	}
+	// not an integer, keep the original text
+	return number
+}
@@ -0,0 +1,64 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func TestJSONIntegers(t *testing.T) {
+	coder := JSONCoder{}
+	str := `{"time":1700000000123,"big":18446744073709551615,"seq":-9007199254740993,"f":1.5}`
+	dict, ok := coder.Decode(str).(StringKeyMap)
+	if !ok {
+		t.Fatalf("failed to decode: %s", str)
+	}
+	if dict["time"] != int64(1700000000123) {
+		t.Errorf("time: %T %v", dict["time"], dict["time"])
+	}
+	if dict["big"] != uint64(18446744073709551615) {
+		t.Errorf("big: %T %v", dict["big"], dict["big"])
+	}
+	if dict["seq"] != int64(-9007199254740993) {
+		t.Errorf("seq: %T %v", dict["seq"], dict["seq"])
+	}
+	if dict["f"] != json.Number("1.5") {
+		t.Errorf("f: %T %v", dict["f"], dict["f"])
+	}
+	// round trip
+	again, _ := coder.Decode(coder.Encode(dict)).(StringKeyMap)
+	for key, value := range dict {
+		if again[key] != value {
+			t.Errorf("round trip %s: got %v, want %v", key, again[key], value)
+		}
+	}
+	if got := coder.Encode("<&>"); got != `"<&>"` {
+		t.Errorf("HTML escaped: %s", got)
+	}
+}
@@ -0,0 +1,217 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"bytes"
+	"encoding/binary"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMimeType is returned when the content type cannot be detected
+const DefaultMimeType = "application/octet-stream"
+
+// DetectMimeType detects the content type from magic bytes and filename extension
+//
+//	Order:
+//	    1. strong signatures (e.g., PNG, JPEG, PDF, ISO-BMFF brands);
+//	    2. filename extension;
+//	    3. weak signatures (e.g., MPEG audio frame sync).
+//
+// Parameters:
+//   - filename: Name of the file (e.g., "avatar.png"), can be empty
+//   - data: Leading bytes of the file content (at least 32 bytes), can be nil
+//
+// Returns: MIME type without parameters, e.g. "image/png"
+func DetectMimeType(filename string, data []byte) string {
+	if mimeType := detectMagic(data); mimeType != "" {
+		return mimeType
+	}
+	if mimeType := MimeTypeByExtension(filepath.Ext(filename)); mimeType != "" {
+		return mimeType
+	}
+	if mimeType := detectWeakMagic(data); mimeType != "" {
+		return mimeType
+	}
+	return DefaultMimeType
+}
+
+// MimeTypeByExtension returns the content type for extension (e.g. ".png")
+//
+// Common types are built-in, others are looked up from the system table;
+// returns empty string if unknown
+func MimeTypeByExtension(ext string) string {
+	ext = strings.ToLower(ext)
+	if mimeType, ok := mimeExtensions[ext]; ok {
+		return mimeType
+	}
+	mimeType := mime.TypeByExtension(ext)
+	if pos := strings.IndexByte(mimeType, ';'); pos > 0 {
+		// remove parameters
+		mimeType = strings.TrimSpace(mimeType[:pos])
+	}
+	return mimeType
+}
+
+var mimeExtensions = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".bmp":  "image/bmp",
+	".heic": "image/heic",
+	".heif": "image/heif",
+	".avif": "image/avif",
+	".svg":  "image/svg+xml",
+	".mp3":  "audio/mpeg",
+	".m4a":  "audio/mp4",
+	".aac":  "audio/aac",
+	".ogg":  "audio/ogg",
+	".wav":  "audio/wav",
+	".mp4":  "video/mp4",
+	".mov":  "video/quicktime",
+	".3gp":  "video/3gpp",
+	".webm": "video/webm",
+	".pdf":  "application/pdf",
+	".zip":  "application/zip",
+	".gz":   "application/gzip",
+	".json": "application/json",
+	".txt":  "text/plain",
+	".html": "text/html",
+}
+
+type mimeMagic struct {
+	magic    []byte
+	mimeType string
+}
+
+var mimeMagics = []mimeMagic{
+	{[]byte("\x89PNG\r\n\x1a\n"), "image/png"},
+	{[]byte("\xFF\xD8\xFF"), "image/jpeg"},
+	{[]byte("GIF87a"), "image/gif"},
+	{[]byte("GIF89a"), "image/gif"},
+	{[]byte("%PDF-"), "application/pdf"},
+	{[]byte("PK\x03\x04"), "application/zip"},
+	{[]byte("\x1F\x8B\x08"), "application/gzip"},
+	{[]byte("OggS\x00"), "audio/ogg"},
+	{[]byte("\x1A\x45\xDF\xA3"), "video/webm"},
+}
+
+// ISO base media file brands ("....ftyp{BRAND}")
+var mimeBrands = map[string]string{
+	"isom": "video/mp4",
+	"iso2": "video/mp4",
+	"iso4": "video/mp4",
+	"iso5": "video/mp4",
+	"iso6": "video/mp4",
+	"mp41": "video/mp4",
+	"mp42": "video/mp4",
+	"avc1": "video/mp4",
+	"dash": "video/mp4",
+	"M4V ": "video/mp4",
+	"M4A ": "audio/mp4",
+	"M4B ": "audio/mp4",
+	"qt  ": "video/quicktime",
+	"3gp4": "video/3gpp",
+	"3gp5": "video/3gpp",
+	"3gp6": "video/3gpp",
+	"heic": "image/heic",
+	"heix": "image/heic",
+	"heim": "image/heic",
+	"heis": "image/heic",
+	"mif1": "image/heif",
+	"msf1": "image/heif",
+	"avif": "image/avif",
+	"avis": "image/avif",
+}
+
+// detectMagic checks strong signatures
+func detectMagic(data []byte) string {
+	for _, item := range mimeMagics {
+		if bytes.HasPrefix(data, item.magic) {
+			return item.mimeType
+		}
+	}
+	if len(data) >= 12 && bytes.Equal(data[0:4], []byte("RIFF")) {
+		switch string(data[8:12]) {
+		case "WEBP":
+			return "image/webp"
+		case "WAVE":
+			return "audio/wav"
+		}
+	}
+	if len(data) >= 12 && bytes.Equal(data[4:8], []byte("ftyp")) {
+		// unknown brands fall back to filename extension
+		return mimeBrands[string(data[8:12])]
+	}
+	if isBitmap(data) {
+		return "image/bmp"
+	}
+	if len(data) >= 10 && bytes.HasPrefix(data, []byte("ID3")) && 2 <= data[3] && data[3] <= 4 && data[4] != 0xFF {
+		// ID3v2 tag: version, revision, flags, syncsafe size
+		if data[6]|data[7]|data[8]|data[9] < 0x80 {
+			return "audio/mpeg"
+		}
+	}
+	return ""
+}
+
+// isBitmap checks BMP file header and the size of DIB header
+func isBitmap(data []byte) bool {
+	if len(data) < 18 || !bytes.HasPrefix(data, []byte("BM")) {
+		return false
+	} else if data[6]|data[7]|data[8]|data[9] != 0 {
+		// reserved
+		return false
+	}
+	switch binary.LittleEndian.Uint32(data[14:18]) {
+	case 12, 40, 52, 56, 64, 108, 124:
+		return true
+	}
+	return false
+}
+
+// detectWeakMagic checks signatures which may appear in other files (e.g., text)
+func detectWeakMagic(data []byte) string {
+	if len(data) < 4 || data[0] != 0xFF || data[1]&0xE0 != 0xE0 {
+		return ""
+	} else if bytes.HasPrefix(data, []byte("\xFF\xFE")) {
+		// UTF-16LE BOM
+		return ""
+	}
+	// MPEG audio frame header
+	version := (data[1] >> 3) & 0x03
+	layer := (data[1] >> 1) & 0x03
+	bitrate := data[2] >> 4
+	sampleRate := (data[2] >> 2) & 0x03
+	if version == 0x01 || layer == 0 || bitrate == 0 || bitrate == 0x0F || sampleRate == 0x03 {
+		return ""
+	}
+	return "audio/mpeg"
+}
@@ -0,0 +1,94 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectMimeType(t *testing.T) {
+	ftyp := func(brand string) string {
+		return "\x00\x00\x00\x1cftyp" + brand + "\x00\x00\x00\x00"
+	}
+	bitmap := "BM\x36\x00\x0c\x00\x00\x00\x00\x00\x36\x00\x00\x00\x28\x00\x00\x00"
+	cases := []struct {
+		filename string
+		data     string
+		expected string
+	}{
+		// strong signatures win over extension
+		{"a.txt", "\x89PNG\r\n\x1a\n\x00\x00", "image/png"},
+		{"", "\xFF\xD8\xFF\xE0", "image/jpeg"},
+		{"", "GIF89a", "image/gif"},
+		{"", "%PDF-1.7", "application/pdf"},
+		{"", "RIFF\x00\x00\x00\x00WEBPVP8 ", "image/webp"},
+		{"", "RIFF\x00\x00\x00\x00WAVEfmt ", "audio/wav"},
+		{"", bitmap, "image/bmp"},
+		{"", "ID3\x04\x00\x00\x00\x00\x01\x00", "audio/mpeg"},
+		// ISO base media brands
+		{"a.mp4", ftyp("isom"), "video/mp4"},
+		{"", ftyp("M4A "), "audio/mp4"},
+		{"", ftyp("qt  "), "video/quicktime"},
+		{"", ftyp("heic"), "image/heic"},
+		{"", ftyp("mif1"), "image/heif"},
+		{"a.mp4", ftyp("avif"), "image/avif"},
+		{"", ftyp("3gp5"), "video/3gpp"},
+		{"clip.mov", ftyp("abcd"), "video/quicktime"},
+		{"", ftyp("abcd"), DefaultMimeType},
+		// loose signatures fall back to extension
+		{"notes.txt", "\xFF\xFEh\x00i\x00", "text/plain"},
+		{"cars.txt", "BMW is a car brand", "text/plain"},
+		{"", "BMW is a car brand", DefaultMimeType},
+		{"tag.txt", "ID3\x09\x00\x00\x00\x00\x00\x00", "text/plain"},
+		{"song.mp3", "\xFF\xFB\x90\x64\x00\x00", "audio/mpeg"},
+		{"", "\xFF\xFB\x90\x64\x00\x00", "audio/mpeg"},
+		{"", "\xFF\xFE\x90\x64\x00\x00", DefaultMimeType},
+		{"", "\xFF\xFF\xFF\xFF\x00\x00", DefaultMimeType},
+		// extension only
+		{"photo.JPG", "", "image/jpeg"},
+		{"a.heif", "", "image/heif"},
+		{"a.3gp", "", "video/3gpp"},
+		{"noext", "", DefaultMimeType},
+	}
+	for _, item := range cases {
+		if got := DetectMimeType(item.filename, []byte(item.data)); got != item.expected {
+			t.Errorf("DetectMimeType(%q, %q): got %s, want %s", item.filename, item.data, got, item.expected)
+		}
+	}
+}
+
+func TestMimeTypeByExtension(t *testing.T) {
+	if got := MimeTypeByExtension(".HTML"); got != "text/html" {
+		t.Errorf(".HTML: %s", got)
+	}
+	if got := MimeTypeByExtension(".css"); got != "" && strings.Contains(got, ";") {
+		t.Errorf("parameters not removed: %s", got)
+	}
+	if got := MimeTypeByExtension(".unknown-ext"); got != "" {
+		t.Errorf("unknown extension: %s", got)
+	}
+}
@@ -0,0 +1,494 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// MessagePackCoder implements ObjectCoder with MessagePack
+//
+// The serialized string holds the raw MessagePack bytes.
+//
+//	Data model:
+//	    StringKeyMap   <-> map (string keys, sorted)
+//	    []any          <-> array
+//	    string         <-> str
+//	    []byte         <-> bin (TransportableData is stored as raw bytes)
+//	    int64          <-> int (uint64 for values larger than MaxInt64)
+//	    float32        <-> float 32
+//	    float64        <-> float 64
+//	    bool, nil      <-> true/false, nil
+//
+// Extension types are not part of the object model, they are decoded as raw bytes.
+//
+// Decode() returns bin values as base64 strings (TED format 0), as JSON does,
+// so TED fields (signature, fingerprint, ...) can be parsed again;
+// use MessagePackDecode() to get the raw bytes.
+type MessagePackCoder struct {
+	//ObjectCoder
+}
+
+// Override
+func (MessagePackCoder) Encode(object any) string {
+	return string(MessagePackEncode(object))
+}
+
+// Override
+func (MessagePackCoder) Decode(str string) any {
+	return encodeBinaryValues(MessagePackDecode([]byte(str)))
+}
+
+func MessagePackEncode(object any) []byte {
+	var buf bytes.Buffer
+	err := writeMsgPackValue(&buf, object)
+	if err != nil {
+		//panic(err)
+		return nil
+	}
+	return buf.Bytes()
+}
+
+func MessagePackDecode(data []byte) any {
+	decoder := &msgPackDecoder{data: data}
+	object, err := decoder.readValue()
+	if err != nil {
+		//panic(err)
+		return nil
+	} else if decoder.pos != len(data) {
+		//panic("unexpected data after MessagePack value")
+		return nil
+	}
+	return object
+}
+
+//
+//  Encoding
+//
+
+func writeMsgPackUint(buf *bytes.Buffer, u uint64) {
+	var tmp [8]byte
+	switch {
+	case u <= 0x7F:
+		// positive fixint
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		buf.WriteByte(0xCC)
+		buf.WriteByte(byte(u))
+	case u <= math.MaxUint16:
+		buf.WriteByte(0xCD)
+		binary.BigEndian.PutUint16(tmp[:2], uint16(u))
+		buf.Write(tmp[:2])
+	case u <= math.MaxUint32:
+		buf.WriteByte(0xCE)
+		binary.BigEndian.PutUint32(tmp[:4], uint32(u))
+		buf.Write(tmp[:4])
+	default:
+		buf.WriteByte(0xCF)
+		binary.BigEndian.PutUint64(tmp[:], u)
+		buf.Write(tmp[:])
+	}
+}
+
+func writeMsgPackInt(buf *bytes.Buffer, i int64) {
+	var tmp [8]byte
+	switch {
+	case i >= 0:
+		writeMsgPackUint(buf, uint64(i))
+	case i >= -32:
+		// negative fixint
+		buf.WriteByte(byte(i))
+	case i >= math.MinInt8:
+		buf.WriteByte(0xD0)
+		buf.WriteByte(byte(i))
+	case i >= math.MinInt16:
+		buf.WriteByte(0xD1)
+		binary.BigEndian.PutUint16(tmp[:2], uint16(i))
+		buf.Write(tmp[:2])
+	case i >= math.MinInt32:
+		buf.WriteByte(0xD2)
+		binary.BigEndian.PutUint32(tmp[:4], uint32(i))
+		buf.Write(tmp[:4])
+	default:
+		buf.WriteByte(0xD3)
+		binary.BigEndian.PutUint64(tmp[:], uint64(i))
+		buf.Write(tmp[:])
+	}
+}
+
+// writeMsgPackHead writes the header for str/bin/array/map with the length
+func writeMsgPackHead(buf *bytes.Buffer, fix byte, fixMax int, codes [3]byte, size int) {
+	var tmp [4]byte
+	switch {
+	case size <= fixMax:
+		buf.WriteByte(fix | byte(size))
+	case size <= math.MaxUint8 && codes[0] != 0:
+		buf.WriteByte(codes[0])
+		buf.WriteByte(byte(size))
+	case size <= math.MaxUint16:
+		buf.WriteByte(codes[1])
+		binary.BigEndian.PutUint16(tmp[:2], uint16(size))
+		buf.Write(tmp[:2])
+	default:
+		buf.WriteByte(codes[2])
+		binary.BigEndian.PutUint32(tmp[:], uint32(size))
+		buf.Write(tmp[:])
+	}
+}
+
+var (
+	msgPackStrCodes   = [3]byte{0xD9, 0xDA, 0xDB}
+	msgPackBinCodes   = [3]byte{0xC4, 0xC5, 0xC6}
+	msgPackArrayCodes = [3]byte{0, 0xDC, 0xDD}
+	msgPackMapCodes   = [3]byte{0, 0xDE, 0xDF}
+)
+
+func writeMsgPackString(buf *bytes.Buffer, str string) {
+	writeMsgPackHead(buf, 0xA0, 31, msgPackStrCodes, len(str))
+	buf.WriteString(str)
+}
+
+func writeMsgPackValue(buf *bytes.Buffer, value any) error {
+	if ValueIsNil(value) {
+		buf.WriteByte(0xC0)
+		return nil
+	}
+	var tmp [8]byte
+	switch v := value.(type) {
+	// boolean
+	case bool:
+		if v {
+			buf.WriteByte(0xC3)
+		} else {
+			buf.WriteByte(0xC2)
+		}
+	// string
+	case string:
+		writeMsgPackString(buf, v)
+	// binary data
+	case []byte:
+		// bin format has no fix type, use -1 to skip it
+		writeMsgPackHead(buf, 0, -1, msgPackBinCodes, len(v))
+		buf.Write(v)
+	// integer
+	case int:
+		writeMsgPackInt(buf, int64(v))
+	case int8:
+		writeMsgPackInt(buf, int64(v))
+	case int16:
+		writeMsgPackInt(buf, int64(v))
+	case int32:
+		writeMsgPackInt(buf, int64(v))
+	case int64:
+		writeMsgPackInt(buf, v)
+	// unsigned integer
+	case uint:
+		writeMsgPackUint(buf, uint64(v))
+	case uint8:
+		writeMsgPackUint(buf, uint64(v))
+	case uint16:
+		writeMsgPackUint(buf, uint64(v))
+	case uint32:
+		writeMsgPackUint(buf, uint64(v))
+	case uint64:
+		writeMsgPackUint(buf, v)
+	// float number
+	case float32:
+		buf.WriteByte(0xCA)
+		binary.BigEndian.PutUint32(tmp[:4], math.Float32bits(v))
+		buf.Write(tmp[:4])
+	case float64:
+		buf.WriteByte(0xCB)
+		binary.BigEndian.PutUint64(tmp[:], math.Float64bits(v))
+		buf.Write(tmp[:])
+	// containers
+	case StringKeyMap:
+		return writeMsgPackMap(buf, v)
+	case []any:
+		writeMsgPackHead(buf, 0x90, 15, msgPackArrayCodes, len(v))
+		for _, item := range v {
+			err := writeMsgPackValue(buf, item)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		object, err := normalizeObject(value)
+		if err != nil {
+			return err
+		}
+		return writeMsgPackValue(buf, object)
+	}
+	return nil
+}
+
+func writeMsgPackMap(buf *bytes.Buffer, dict StringKeyMap) error {
+	// sorted keys, so the same map always produces the same bytes
+	keys := MapKeys(dict)
+	sort.Strings(keys)
+	writeMsgPackHead(buf, 0x80, 15, msgPackMapCodes, len(keys))
+	for _, key := range keys {
+		writeMsgPackString(buf, key)
+		err := writeMsgPackValue(buf, dict[key])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//
+//  Decoding
+//
+
+const msgPackMaxDepth = 512
+
+var errMsgPackTruncated = errors.New("MessagePack data truncated")
+
+type msgPackDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func (decoder *msgPackDecoder) readBytes(n uint64) ([]byte, error) {
+	if n > uint64(len(decoder.data)-decoder.pos) {
+		return nil, errMsgPackTruncated
+	}
+	start := decoder.pos
+	decoder.pos += int(n)
+	return decoder.data[start:decoder.pos], nil
+}
+
+// readUint reads a big-endian unsigned integer with size in bytes (1, 2, 4 or 8)
+func (decoder *msgPackDecoder) readUint(size int) (uint64, error) {
+	b, err := decoder.readBytes(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	default:
+		return binary.BigEndian.Uint64(b), nil
+	}
+}
+
+func (decoder *msgPackDecoder) readValue() (any, error) {
+	head, err := decoder.readBytes(1)
+	if err != nil {
+		return nil, err
+	}
+	code := head[0]
+	switch {
+	case code <= 0x7F:
+		// positive fixint
+		return int64(code), nil
+	case code <= 0x8F:
+		return decoder.readMap(uint64(code & 0x0F))
+	case code <= 0x9F:
+		return decoder.readArray(uint64(code & 0x0F))
+	case code <= 0xBF:
+		return decoder.readString(uint64(code & 0x1F))
+	case code >= 0xE0:
+		// negative fixint
+		return int64(int8(code)), nil
+	}
+	switch code {
+	case 0xC0:
+		return nil, nil
+	case 0xC2:
+		return false, nil
+	case 0xC3:
+		return true, nil
+	// bin 8/16/32
+	case 0xC4, 0xC5, 0xC6:
+		size, err := decoder.readUint(1 << (code - 0xC4))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.readBinary(size)
+	// ext 8/16/32
+	case 0xC7, 0xC8, 0xC9:
+		size, err := decoder.readUint(1 << (code - 0xC7))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.readExtension(size)
+	// float 32/64
+	case 0xCA:
+		bits, err := decoder.readUint(4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(uint32(bits)), nil
+	case 0xCB:
+		bits, err := decoder.readUint(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(bits), nil
+	// uint 8/16/32/64
+	case 0xCC, 0xCD, 0xCE, 0xCF:
+		u, err := decoder.readUint(1 << (code - 0xCC))
+		if err != nil {
+			return nil, err
+		} else if u > math.MaxInt64 {
+			return u, nil
+		}
+		return int64(u), nil
+	// int 8/16/32/64
+	case 0xD0:
+		u, err := decoder.readUint(1)
+		return int64(int8(u)), err
+	case 0xD1:
+		u, err := decoder.readUint(2)
+		return int64(int16(u)), err
+	case 0xD2:
+		u, err := decoder.readUint(4)
+		return int64(int32(u)), err
+	case 0xD3:
+		u, err := decoder.readUint(8)
+		return int64(u), err
+	// fixext 1/2/4/8/16
+	case 0xD4, 0xD5, 0xD6, 0xD7, 0xD8:
+		return decoder.readExtension(1 << (code - 0xD4))
+	// str 8/16/32
+	case 0xD9, 0xDA, 0xDB:
+		size, err := decoder.readUint(1 << (code - 0xD9))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.readString(size)
+	// array 16/32
+	case 0xDC, 0xDD:
+		size, err := decoder.readUint(2 << (code - 0xDC))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.readArray(size)
+	// map 16/32
+	case 0xDE, 0xDF:
+		size, err := decoder.readUint(2 << (code - 0xDE))
+		if err != nil {
+			return nil, err
+		}
+		return decoder.readMap(size)
+	default: // 0xC1
+		return nil, errors.New("MessagePack type code error")
+	}
+}
+
+func (decoder *msgPackDecoder) readString(size uint64) (string, error) {
+	b, err := decoder.readBytes(size)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (decoder *msgPackDecoder) readBinary(size uint64) ([]byte, error) {
+	b, err := decoder.readBytes(size)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{}, b...), nil
+}
+
+// readExtension skips the extension type and returns its payload
+func (decoder *msgPackDecoder) readExtension(size uint64) ([]byte, error) {
+	_, err := decoder.readBytes(1)
+	if err != nil {
+		return nil, err
+	}
+	return decoder.readBinary(size)
+}
+
+func (decoder *msgPackDecoder) readNested() (any, error) {
+	decoder.depth++
+	if decoder.depth > msgPackMaxDepth {
+		return nil, errors.New("MessagePack nesting too deep")
+	}
+	value, err := decoder.readValue()
+	decoder.depth--
+	return value, err
+}
+
+// checkCount prevents allocating for counts larger than the remaining data
+func (decoder *msgPackDecoder) checkCount(count uint64) error {
+	if count > uint64(len(decoder.data)-decoder.pos) {
+		return errMsgPackTruncated
+	}
+	return nil
+}
+
+func (decoder *msgPackDecoder) readArray(count uint64) ([]any, error) {
+	err := decoder.checkCount(count)
+	if err != nil {
+		return nil, err
+	}
+	array := make([]any, count)
+	for index := range array {
+		array[index], err = decoder.readNested()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return array, nil
+}
+
+func (decoder *msgPackDecoder) readMap(count uint64) (StringKeyMap, error) {
+	err := decoder.checkCount(count)
+	if err != nil {
+		return nil, err
+	}
+	dict := make(StringKeyMap, count)
+	for ; count > 0; count-- {
+		key, err := decoder.readNested()
+		if err != nil {
+			return nil, err
+		}
+		text, ok := key.(string)
+		if !ok {
+			return nil, errors.New("MessagePack map key is not a string")
+		}
+		dict[text], err = decoder.readNested()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dict, nil
+}
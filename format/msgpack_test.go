@@ -0,0 +1,195 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func TestMessagePackEncodeVectors(t *testing.T) {
+	cases := []struct {
+		value    any
+		expected string
+	}{
+		{nil, "c0"},
+		{false, "c2"},
+		{true, "c3"},
+		{0, "00"},
+		{127, "7f"},
+		{128, "cc80"},
+		{256, "cd0100"},
+		{65536, "ce00010000"},
+		{int64(4294967296), "cf0000000100000000"},
+		{uint64(math.MaxUint64), "cfffffffffffffffff"},
+		{-1, "ff"},
+		{-32, "e0"},
+		{-33, "d0df"},
+		{-129, "d1ff7f"},
+		{-32769, "d2ffff7fff"},
+		{int64(math.MinInt64), "d38000000000000000"},
+		{float32(1.5), "ca3fc00000"},
+		{1.5, "cb3ff8000000000000"},
+		{"", "a0"},
+		{"a", "a161"},
+		{strings.Repeat("x", 32), "d920" + strings.Repeat("78", 32)},
+		{[]byte{1, 2}, "c4020102"},
+		{[]any{}, "90"},
+		{[]any{1, "a"}, "9201a161"},
+		{StringKeyMap{}, "80"},
+		{StringKeyMap{"b": 2, "a": 1}, "82a16101a16202"},
+	}
+	for _, item := range cases {
+		got := hex.EncodeToString(MessagePackEncode(item.value))
+		if got != item.expected {
+			t.Errorf("MessagePack encode %#v: got %s, want %s", item.value, got, item.expected)
+		}
+	}
+	// 16-bit and 32-bit container headers
+	long := make([]any, 16)
+	if got := MessagePackEncode(long); !bytes.HasPrefix(got, []byte{0xDC, 0x00, 0x10}) {
+		t.Errorf("MessagePack array16 header: %x", got[:3])
+	}
+	bin := make([]byte, 65536)
+	if got := MessagePackEncode(bin); !bytes.HasPrefix(got, []byte{0xC6, 0x00, 0x01, 0x00, 0x00}) {
+		t.Errorf("MessagePack bin32 header: %x", got[:5])
+	}
+}
+
+func TestMessagePackDecodeVectors(t *testing.T) {
+	cases := []struct {
+		data     string
+		expected any
+	}{
+		{"c0", nil},
+		{"c3", true},
+		{"7f", int64(127)},
+		{"e0", int64(-32)},
+		{"cc80", int64(128)},
+		{"cfffffffffffffffff", uint64(math.MaxUint64)},
+		{"d0df", int64(-33)},
+		{"d38000000000000000", int64(math.MinInt64)},
+		{"ca3fc00000", float32(1.5)},
+		{"cb3ff8000000000000", 1.5},
+		{"a3616263", "abc"},
+		{"d903616263", "abc"},
+		{"c4020102", []byte{1, 2}},
+		{"d40102", []byte{2}},                     // fixext 1
+		{"c7020103ff", []byte{3, 0xFF}},           // ext 8
+		{"dc00020102", []any{int64(1), int64(2)}}, // array16
+		{"de0001a161c0", StringKeyMap{"a": nil}},  // map16
+		{"81a16192c3c2", StringKeyMap{"a": []any{true, false}}},
+	}
+	for _, item := range cases {
+		got := MessagePackDecode(mustHex(t, item.data))
+		if !reflect.DeepEqual(got, item.expected) {
+			t.Errorf("MessagePack decode %s: got %#v, want %#v", item.data, got, item.expected)
+		}
+	}
+}
+
+func TestMessagePackMalformed(t *testing.T) {
+	cases := []string{
+		"",           // empty
+		"c1",         // never used
+		"cd01",       // truncated uint16
+		"a361",       // truncated string
+		"92c0",       // truncated array
+		"81a161",     // missing map value
+		"8101c0",     // non-string key
+		"c0c0",       // trailing data
+		"ddffffffff", // array too large
+	}
+	for _, item := range cases {
+		if got := MessagePackDecode(mustHex(t, item)); got != nil {
+			t.Errorf("MessagePack decode %q: expected nil, got %#v", item, got)
+		}
+	}
+	deep := bytes.Repeat([]byte{0x91}, 1000)
+	deep = append(deep, 0xC0)
+	if got := MessagePackDecode(deep); got != nil {
+		t.Errorf("MessagePack deep nesting: expected nil")
+	}
+}
+
+func TestMessagePackRoundTrip(t *testing.T) {
+	object := StringKeyMap{
+		"did":  "moky@4DnqXWdTV8wuZgfqSCX9GjE2kNq7HJrUgQ",
+		"time": int64(1700000000123),
+		"big":  uint64(math.MaxUint64),
+		"neg":  int64(-123456789),
+		"pi":   3.141592653589793,
+		"ok":   true,
+		"none": nil,
+		"data": []byte{0, 1, 2, 0xFF},
+		"text": strings.Repeat("中文", 100),
+		"list": []any{"a", int64(1), []any{}, StringKeyMap{}},
+	}
+	got := MessagePackDecode(MessagePackEncode(object))
+	if !reflect.DeepEqual(got, object) {
+		t.Fatalf("MessagePack round trip:\n got %#v\nwant %#v", got, object)
+	}
+	// object coder returns binary data as base64 string
+	coder := MessagePackCoder{}
+	got = coder.Decode(coder.Encode(object))
+	object["data"] = "AAEC/w=="
+	if !reflect.DeepEqual(got, object) {
+		t.Fatalf("MessagePack coder round trip:\n got %#v\nwant %#v", got, object)
+	}
+}
+
+func TestMessagePackTransportableData(t *testing.T) {
+	signature := NewEncodedData([]byte{0, 1, 2, 0xFF}, nil)
+	avatar := NewEncodedData([]byte("PNG"), NewDataHeader("image/png", "base64"))
+	object := StringKeyMap{
+		"signature": signature,
+		"key":       StringKeyMap{"fingerprint": signature},
+		"avatars":   []any{avatar},
+	}
+	coder := MessagePackCoder{}
+	got := FetchMap(coder.Decode(coder.Encode(object)))
+	if got == nil {
+		t.Fatalf("MessagePack failed to decode TED object")
+	}
+	factory := EncodedDataFactory{}
+	fingerprint := FetchMap(got["key"])["fingerprint"]
+	for _, value := range []any{got["signature"], fingerprint} {
+		ted := factory.ParseTransportableData(FetchString(value))
+		if ted == nil || !bytes.Equal(ted.Bytes(), signature.Bytes()) {
+			t.Errorf("MessagePack TED not restored: %#v", value)
+		}
+	}
+	ted := factory.ParseTransportableData(FetchString(FetchList(got["avatars"])[0]))
+	if ted == nil || ted.MimeType() != "image/png" || string(ted.Bytes()) != "PNG" {
+		t.Errorf("MessagePack data URI not restored: %#v", got["avatars"])
+	}
+}
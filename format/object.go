@@ -25,12 +25,20 @@
  */
 package format
 
-import . "github.com/dimchat/mkm-go/types"
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	. "github.com/dimchat/mkm-go/types"
+)
 
 // ObjectCoder defines the interface for object serialization/deserialization
 //
 //	Supported formats include:
-//	    JSON, XML, ...
+//	    JSON, CBOR, MessagePack, XML, ...
+//
+// For binary formats (e.g. CBOR), the serialized string holds the raw bytes.
 type ObjectCoder interface {
 
 	// Encode converts a Map or List object to its serialized string form
@@ -54,10 +62,11 @@ type ObjectCoder interface {
 //  JsON
 //
 
-var jsonCoder ObjectCoder = nil
+var jsonCoder ObjectCoder = &JSONCoder{}
 
 func SetJSONCoder(coder ObjectCoder) {
 	jsonCoder = coder
+	SetObjectCoder("json", coder)
 }
 
 func JSONEncode(object any) string {
@@ -80,3 +89,93 @@ func JSONDecodeMap(str string) StringKeyMap {
 	dict := jsonCoder.Decode(str)
 	return FetchMap(dict)
 }
+
+//
+//  Object Coders
+//
+
+var objectCoders = map[string]ObjectCoder{
+	"json": jsonCoder,
+	"cbor": &CBORCoder{},
+
+	"msgpack":     &MessagePackCoder{},
+	"messagepack": &MessagePackCoder{},
+}
+
+// SetObjectCoder registers an object coder with format name
+//
+// Names are case-insensitive, e.g. "JSON", "cbor", "msgpack", ...
+func SetObjectCoder(name string, coder ObjectCoder) {
+	objectCoders[coderName(name)] = coder
+}
+
+// GetObjectCoder returns the object coder registered with format name (nil if not found)
+func GetObjectCoder(name string) ObjectCoder {
+	return objectCoders[coderName(name)]
+}
+
+func coderName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.ReplaceAll(name, "-", "")
+	return strings.ReplaceAll(name, "_", "")
+}
+
+// normalizeObject converts a value outside the basic object model into one of:
+// nil, bool, int64, uint64, float64, string, []byte, StringKeyMap or []any
+//
+// Binary transportable data without a data URI header is kept as raw bytes,
+// so binary coders can store it without base64 encoding.
+func normalizeObject(value any) (any, error) {
+	switch v := value.(type) {
+	case TransportableData:
+		str := v.String()
+		if strings.HasPrefix(str, "data:") {
+			return str, nil
+		}
+		return v.Bytes(), nil
+	case Mapper:
+		return v.Map(), nil
+	case Stringer:
+		return v.String(), nil
+	case Time:
+		return TimeToFloat64(v), nil
+	case json.Number:
+		if number, ok := convertJSONNumber(v).(json.Number); ok {
+			return number.Float64()
+		}
+		return convertJSONNumber(v), nil
+	}
+	// reflected maps/lists
+	switch v := Unwrap(value).(type) {
+	case StringKeyMap, []any, string:
+		return v, nil
+	}
+	// let the standard encoder handle it, e.g. structs
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	object := decodeJSON(string(data))
+	if object == nil {
+		return nil, errors.New("failed to normalize object")
+	}
+	return object, nil
+}
+
+// encodeBinaryValues replaces raw bytes in the decoded object with base64 strings,
+// which is how TransportableData (format 0) and JSON serialize binary data
+func encodeBinaryValues(value any) any {
+	switch v := value.(type) {
+	case []byte:
+		return Base64Encode(v)
+	case StringKeyMap:
+		for key, item := range v {
+			v[key] = encodeBinaryValues(item)
+		}
+	case []any:
+		for index, item := range v {
+			v[index] = encodeBinaryValues(item)
+		}
+	}
+	return value
+}
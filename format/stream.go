@@ -0,0 +1,411 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// StreamData is a TransportableData backed by io.Reader or local file
+//
+// The payload is encoded/decoded in streaming fashion, so large files
+// can be written into (or read from) a TED string without holding both
+// the raw data and the encoded text in memory:
+//
+//	ted := NewFileStreamData("/tmp/movie.mp4", NewDataHeader("video/mp4", "base64"))
+//	_, err := ted.EncodeTo(writer)  // "data:video/mp4;base64,{BASE64_ENCODE}"
+//
+// Only "base64" and "hex" can be streamed; Bytes() and String() still
+// work for all encodings, but they load the whole payload into memory.
+//
+// Data backed by a one-shot reader can be consumed only once: call one of
+// WriteTo(), EncodeTo(), String() or Bytes(), later calls will fail
+// (and Size() reads the data when the size is unknown).
+// Use NewBufferedStreamData() to keep the data for repeated calls,
+// or NewFileStreamData(), which reads the file again for every call.
+type StreamData struct {
+	//TransportableData
+
+	header *DataHeader // nil for plain base64 string
+
+	path   string    // local file path
+	reader io.Reader // one-shot reader
+	keep   bool      // buffer the one-shot reader for repeated calls
+	size   int64     // -1 means unknown
+
+	data []byte // buffered data after the one-shot reader consumed
+	err  error  // error while consuming the one-shot reader
+}
+
+// NewStreamData creates TED with a one-shot reader, which can be consumed only once
+//
+// Parameters:
+//   - reader: Raw data source (read only once, not buffered)
+//   - size: Length of raw data (-1 if unknown)
+//   - header: Data URI header (nil for plain base64 string), copied
+func NewStreamData(reader io.Reader, size int64, header *DataHeader) *StreamData {
+	return &StreamData{
+		header: header.Clone(),
+		reader: reader,
+		size:   size,
+	}
+}
+
+// NewBufferedStreamData creates TED with a one-shot reader, which is buffered
+// while it's consumed, so all accessors can be called repeatedly
+//
+// Note: the whole payload is kept in memory after the first read
+func NewBufferedStreamData(reader io.Reader, size int64, header *DataHeader) *StreamData {
+	ted := NewStreamData(reader, size, header)
+	ted.keep = true
+	return ted
+}
+
+// NewFileStreamData creates TED with a local file, which is reopened for every read
+//
+// Parameters:
+//   - path: Local file path
+//   - header: Data URI header (nil for plain base64 string), copied
+func NewFileStreamData(path string, header *DataHeader) *StreamData {
+	var size int64 = -1
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+	return &StreamData{
+		header: header.Clone(),
+		path:   path,
+		size:   size,
+	}
+}
+
+// open returns the raw data source
+func (ted *StreamData) open() (io.ReadCloser, error) {
+	if ted.data != nil {
+		return io.NopCloser(bytes.NewReader(ted.data)), nil
+	} else if ted.path != "" {
+		return os.Open(ted.path)
+	} else if ted.err != nil {
+		return nil, ted.err
+	} else if ted.reader == nil {
+		return nil, errStreamConsumed
+	}
+	reader := ted.reader
+	ted.reader = nil
+	return &oneShotReader{ted: ted, reader: reader}, nil
+}
+
+var errStreamConsumed = errors.New("stream data consumed")
+
+// oneShotReader records the result of the one-shot reader,
+// and keeps the data read from it when the stream data is buffered
+type oneShotReader struct {
+	ted    *StreamData
+	reader io.Reader
+	buffer bytes.Buffer
+	count  int64
+	done   bool
+}
+
+func (br *oneShotReader) Read(p []byte) (int, error) {
+	n, err := br.reader.Read(p)
+	br.count += int64(n)
+	if br.ted.keep {
+		br.buffer.Write(p[:n])
+	}
+	if err == io.EOF {
+		br.finish(nil)
+	} else if err != nil {
+		br.finish(err)
+	}
+	return n, err
+}
+
+func (br *oneShotReader) Close() error {
+	if !br.done {
+		// not read to the end
+		br.finish(errors.New("stream data not read completely"))
+	}
+	if closer, ok := br.reader.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (br *oneShotReader) finish(err error) {
+	if br.done {
+		return
+	}
+	br.done = true
+	if err != nil {
+		br.ted.err = err
+		return
+	}
+	br.ted.size = br.count
+	if !br.ted.keep {
+		br.ted.err = errStreamConsumed
+		return
+	}
+	// the buffer is not written any more, take its bytes without copying
+	data := br.buffer.Bytes()
+	if data == nil {
+		data = []byte{}
+	}
+	br.ted.data = data
+}
+
+// WriteTo writes the raw data to w
+func (ted *StreamData) WriteTo(w io.Writer) (int64, error) {
+	reader, err := ted.open()
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+	return io.Copy(w, reader)
+}
+
+// EncodeTo writes the whole TED string (with data URI header if exists) to w
+//
+// Returns: number of bytes written
+func (ted *StreamData) EncodeTo(w io.Writer) (int64, error) {
+	counter := &countWriter{writer: w}
+	if ted.header != nil {
+		if _, err := io.WriteString(counter, ted.header.String()); err != nil {
+			return counter.count, err
+		}
+	}
+	encoder, err := newStreamEncoder(ted.Encoding(), counter)
+	if err != nil {
+		return counter.count, err
+	}
+	if _, err = ted.WriteTo(encoder); err != nil {
+		return counter.count, err
+	}
+	err = encoder.Close()
+	return counter.count, err
+}
+
+//-------- IObject
+
+// Override
+func (ted *StreamData) Equal(other any) bool {
+	if other == nil {
+		return ted.IsEmpty()
+	}
+	switch v := other.(type) {
+	case TransportableData:
+		if v == TransportableData(ted) {
+			return true
+		}
+		return bytes.Equal(ted.Bytes(), v.Bytes())
+	case fmt.Stringer:
+		return ted.String() == v.String()
+	case string:
+		return ted.String() == v
+	default:
+		// type not matched
+		return false
+	}
+}
+
+//-------- Stringer
+
+// Override
+func (ted *StreamData) IsEmpty() bool {
+	return ted.Size() == 0
+}
+
+// String returns the whole TED string
+//
+// Note: this loads the encoded payload into memory, use EncodeTo() for large data
+func (ted *StreamData) String() string {
+	if isStreamEncoding(ted.Encoding()) {
+		var sb strings.Builder
+		if _, err := ted.EncodeTo(&sb); err != nil {
+			//panic(err)
+			return ""
+		}
+		return sb.String()
+	}
+	body, ok := EncodeDataURIBody(ted.header, ted.Bytes())
+	if !ok {
+		return ""
+	}
+	return ted.header.String() + body
+}
+
+//-------- TransportableResource
+
+// Override
+func (ted *StreamData) Serialize() any {
+	return ted.String()
+}
+
+//-------- TransportableData
+
+// Override
+func (ted *StreamData) Encoding() string {
+	if ted.header == nil {
+		return "base64"
+	}
+	return ted.header.Encoding()
+}
+
+// Override
+func (ted *StreamData) MimeType() string {
+	if ted.header == nil {
+		return ""
+	}
+	return ted.header.MimeType()
+}
+
+// Override
+func (ted *StreamData) Filename() string {
+	if ted.header == nil {
+		return ""
+	}
+	return ted.header.Filename()
+}
+
+// Header returns a copy of the data URI header
+func (ted *StreamData) Header() *DataHeader {
+	return ted.header.Clone()
+}
+
+// Bytes returns the raw data
+//
+// Note: this loads the whole payload into memory (and keeps it for buffered reader)
+func (ted *StreamData) Bytes() []byte {
+	if ted.data != nil {
+		return ted.data
+	}
+	reader, err := ted.open()
+	if err != nil {
+		//panic(err)
+		return nil
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		//panic(err)
+		return nil
+	}
+	return data
+}
+
+// Override
+func (ted *StreamData) Size() int {
+	if ted.size < 0 {
+		return len(ted.Bytes())
+	}
+	return int(ted.size)
+}
+
+//
+//  Streaming decoder
+//
+
+// DecodeStreamData reads a TED string (format 0 or 1) from r,
+// and writes the decoded raw data to w
+//
+// Returns: data URI header (nil for format 0), number of raw bytes written
+func DecodeStreamData(r io.Reader, w io.Writer) (*DataHeader, int64, error) {
+	reader := bufio.NewReader(r)
+	prefix, err := reader.Peek(5)
+	if err != nil && err != io.EOF {
+		return nil, 0, err
+	}
+	var header *DataHeader
+	encoding := "base64"
+	if strings.EqualFold(string(prefix), "data:") {
+		head, err := reader.ReadString(',')
+		if err != nil {
+			return nil, 0, errors.New("data URI header not terminated")
+		}
+		header, _ = ParseDataURI(head)
+		encoding = header.Encoding()
+	}
+	decoder, err := newStreamDecoder(encoding, reader)
+	if err != nil {
+		return header, 0, err
+	}
+	count, err := io.Copy(w, decoder)
+	return header, count, err
+}
+
+func isStreamEncoding(encoding string) bool {
+	switch coderName(encoding) {
+	case "base64", "hex":
+		return true
+	}
+	return false
+}
+
+func newStreamEncoder(encoding string, w io.Writer) (io.WriteCloser, error) {
+	switch coderName(encoding) {
+	case "base64":
+		return base64.NewEncoder(base64.StdEncoding, w), nil
+	case "hex":
+		return nopWriteCloser{hex.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("encoding not support streaming: %q", encoding)
+}
+
+func newStreamDecoder(encoding string, r io.Reader) (io.Reader, error) {
+	switch coderName(encoding) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r), nil
+	case "hex":
+		return hex.NewDecoder(r), nil
+	}
+	return nil, fmt.Errorf("encoding not support streaming: %q", encoding)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+type countWriter struct {
+	writer io.Writer
+	count  int64
+}
+
+func (cw *countWriter) Write(p []byte) (int, error) {
+	n, err := cw.writer.Write(p)
+	cw.count += int64(n)
+	return n, err
+}
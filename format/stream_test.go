@@ -0,0 +1,169 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStreamDataRepeatable(t *testing.T) {
+	ted := NewBufferedStreamData(strings.NewReader("hello"), -1, nil)
+	for i := 0; i < 3; i++ {
+		if got := ted.String(); got != "aGVsbG8=" {
+			t.Fatalf("String() #%d: %q", i, got)
+		}
+	}
+	if got := string(ted.Bytes()); got != "hello" {
+		t.Errorf("Bytes(): %q", got)
+	}
+	if got := ted.Serialize(); got != "aGVsbG8=" {
+		t.Errorf("Serialize(): %v", got)
+	}
+	if ted.Size() != 5 {
+		t.Errorf("Size(): %d", ted.Size())
+	}
+	var buf bytes.Buffer
+	if _, err := ted.WriteTo(&buf); err != nil || buf.String() != "hello" {
+		t.Errorf("WriteTo(): %q, %v", buf.String(), err)
+	}
+}
+
+func TestStreamDataOneShot(t *testing.T) {
+	ted := NewStreamData(strings.NewReader("hello"), 5, nil)
+	if got := ted.String(); got != "aGVsbG8=" {
+		t.Fatalf("String(): %q", got)
+	}
+	if ted.Size() != 5 || ted.data != nil {
+		t.Errorf("one-shot data buffered: %d, %q", ted.Size(), ted.data)
+	}
+	// consumed
+	if got := ted.String(); got != "" {
+		t.Errorf("String() after consumed: %q", got)
+	}
+	if _, err := ted.WriteTo(io.Discard); err != errStreamConsumed {
+		t.Errorf("WriteTo() after consumed: %v", err)
+	}
+	// empty reader
+	empty := NewBufferedStreamData(strings.NewReader(""), -1, nil)
+	if !empty.IsEmpty() || empty.String() != "" || empty.Bytes() == nil {
+		t.Errorf("empty stream data: %q", empty.String())
+	}
+}
+
+func TestStreamDataHeader(t *testing.T) {
+	header := NewDataHeader("text/plain", "hex")
+	ted := NewStreamData(strings.NewReader("hi"), 2, header)
+	header.SetEncoding("base64")
+	ted.Header().SetEncoding("base64")
+	if got := ted.String(); got != "data:text/plain;hex,6869" {
+		t.Errorf("String(): %s", got)
+	}
+	if ted.MimeType() != "text/plain" || ted.Encoding() != "hex" {
+		t.Errorf("header: %s, %s", ted.MimeType(), ted.Encoding())
+	}
+}
+
+type failingReader struct {
+	data []byte
+}
+
+func (reader *failingReader) Read(p []byte) (int, error) {
+	if len(reader.data) == 0 {
+		return 0, errors.New("connection reset")
+	}
+	n := copy(p, reader.data)
+	reader.data = reader.data[n:]
+	return n, nil
+}
+
+func TestStreamDataReaderError(t *testing.T) {
+	ted := NewStreamData(&failingReader{data: []byte("partial")}, -1, nil)
+	var buf bytes.Buffer
+	if _, err := ted.EncodeTo(&buf); err == nil {
+		t.Fatalf("expected reader error")
+	}
+	// later calls report the error instead of returning partial data
+	if _, err := ted.WriteTo(io.Discard); err == nil || err.Error() != "connection reset" {
+		t.Errorf("WriteTo() after error: %v", err)
+	}
+	if got := ted.String(); got != "" {
+		t.Errorf("String() after error: %q", got)
+	}
+}
+
+func TestFileStreamData(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "movie.bin")
+	data := bytes.Repeat([]byte{0, 1, 2, 0xFF}, 10000)
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	ted := NewFileStreamData(path, NewDataHeader("application/octet-stream", "base64"))
+	if ted.Size() != len(data) {
+		t.Errorf("Size(): %d", ted.Size())
+	}
+	var encoded strings.Builder
+	if _, err := ted.EncodeTo(&encoded); err != nil {
+		t.Fatal(err)
+	}
+	if encoded.String() != ted.String() {
+		t.Errorf("EncodeTo() and String() differ")
+	}
+	// decode in streaming fashion
+	var decoded bytes.Buffer
+	header, count, err := DecodeStreamData(strings.NewReader(encoded.String()), &decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.MimeType() != "application/octet-stream" || count != int64(len(data)) {
+		t.Errorf("decoded: %v, %d", header, count)
+	}
+	if !bytes.Equal(decoded.Bytes(), data) {
+		t.Errorf("decoded data not match")
+	}
+	if !ted.Equal(NewEncodedData(data, nil)) {
+		t.Errorf("not equal to encoded data")
+	}
+}
+
+func TestDecodeStreamDataPlain(t *testing.T) {
+	var buf bytes.Buffer
+	header, count, err := DecodeStreamData(strings.NewReader("aGVsbG8="), &buf)
+	if err != nil || header != nil || count != 5 || buf.String() != "hello" {
+		t.Errorf("plain base64: %v, %d, %v, %q", header, count, err, buf.String())
+	}
+	if _, _, err = DecodeStreamData(strings.NewReader("data:text/plain;base64"), &buf); err == nil {
+		t.Errorf("expected error for unterminated header")
+	}
+	if _, _, err = DecodeStreamData(strings.NewReader("data:text/plain;base58,abc"), &buf); err == nil {
+		t.Errorf("expected error for unsupported encoding")
+	}
+}
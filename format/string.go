@@ -25,6 +25,8 @@
  */
 package format
 
+import "encoding/binary"
+
 // StringCoder defines the interface for string encoding/decoding between string and binary data
 //
 //	Supported character encodings include:
@@ -52,10 +54,11 @@ type StringCoder interface {
 //  UTF-8
 //
 
-var utf8Coder StringCoder = nil
+var utf8Coder StringCoder = &UTF8Coder{}
 
 func SetUTF8Coder(coder StringCoder) {
 	utf8Coder = coder
+	SetStringCoder("UTF-8", coder)
 }
 
 func UTF8Encode(string string) []byte {
@@ -65,3 +68,32 @@ func UTF8Encode(string string) []byte {
 func UTF8Decode(bytes []byte) string {
 	return utf8Coder.Decode(bytes)
 }
+
+//
+//  String Coders
+//
+
+var stringCoders = map[string]StringCoder{
+	"utf8": utf8Coder,
+
+	"utf16":   &UTF16Coder{ByteOrder: binary.BigEndian, BOM: true},
+	"utf16be": &UTF16Coder{ByteOrder: binary.BigEndian},
+	"utf16le": &UTF16Coder{ByteOrder: binary.LittleEndian},
+
+	"gb2312":  &GB18030Coder{GBK: true},
+	"gbk":     &GB18030Coder{GBK: true},
+	"cp936":   &GB18030Coder{GBK: true},
+	"gb18030": &GB18030Coder{},
+}
+
+// SetStringCoder registers a string coder with charset name
+//
+// Names are case-insensitive, and '-' or '_' are ignored, e.g. "UTF-8", "utf8", "GB_2312", ...
+func SetStringCoder(charset string, coder StringCoder) {
+	stringCoders[coderName(charset)] = coder
+}
+
+// GetStringCoder returns the string coder registered with charset name (nil if not found)
+func GetStringCoder(charset string) StringCoder {
+	return stringCoders[coderName(charset)]
+}
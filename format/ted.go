@@ -72,6 +72,8 @@ type TransportableResource interface {
 //	Supported serialization formats (subset of TransportableResource):
 //	    0. "{BASE64_ENCODE}"
 //	    1. "data:image/png;base64,{BASE64_ENCODE}"
+//	       "data:image/png;filename=avatar.png;base64,{BASE64_ENCODE}"
+//	       "data:text/plain;charset=utf-8;hex,{HEX_ENCODE}"
 type TransportableData interface {
 	Stringer
 	TransportableResource
@@ -85,6 +87,18 @@ type TransportableData interface {
 	// Typical return value: "base64"
 	Encoding() string
 
+	// MimeType returns the media type declared in the data URI header
+	// Typical return value: "image/png" (empty for format 0)
+	MimeType() string
+
+	// Filename returns the "filename" parameter in the data URI header
+	// (empty if not declared)
+	Filename() string
+
+	// Header returns a copy of the data URI header with all parameters in order
+	// (nil for format 0); changing it does not affect the data
+	Header() *DataHeader
+
 	// Bytes returns the original raw (plaintext) binary data
 	Bytes() []byte
 
@@ -96,6 +110,7 @@ type TransportableData interface {
 	// Possible return values:
 	//   - "{BASE64_ENCODE}"
 	//   - "data:image/png;base64,{BASE64_ENCODE}"
+	//   - "data:image/png;filename=avatar.png;base64,{BASE64_ENCODE}"
 	//String() string
 
 	// Serialize implements TransportableResource interface (aliases String())
@@ -0,0 +1,266 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DataParameter is a "name=value" pair in the data URI header
+type DataParameter struct {
+	Name  string
+	Value string
+}
+
+// DataHeader is the header part of a data URI
+//
+//	Format:
+//	    "data:{MIME_TYPE};{NAME}={VALUE};...;{ENCODING},{DATA}"
+//
+//	Examples:
+//	    "data:image/png;base64,{BASE64_ENCODE}"
+//	    "data:image/png;filename=avatar.png;base64,{BASE64_ENCODE}"
+//	    "data:text/plain;charset=utf-8;hex,{HEX_ENCODE}"
+//	    "data:,Hello%2C%20World"
+//
+// Parameters are kept in their original order, so parsing and serializing
+// a header will not change it. When the encoding is empty, the data part is
+// percent-encoded text (RFC 2397).
+type DataHeader struct {
+	mimeType   string
+	parameters []DataParameter
+	encoding   string
+}
+
+func NewDataHeader(mimeType string, encoding string) *DataHeader {
+	return &DataHeader{
+		mimeType: mimeType,
+		encoding: encoding,
+	}
+}
+
+// Clone returns a copy of the header
+func (header *DataHeader) Clone() *DataHeader {
+	if header == nil {
+		return nil
+	}
+	return &DataHeader{
+		mimeType:   header.mimeType,
+		parameters: header.Parameters(),
+		encoding:   header.encoding,
+	}
+}
+
+// MimeType returns the media type, e.g. "image/png"
+func (header *DataHeader) MimeType() string {
+	return header.mimeType
+}
+
+func (header *DataHeader) SetMimeType(mimeType string) {
+	header.mimeType = mimeType
+}
+
+// Encoding returns the data encoding, e.g. "base64", "base58", "hex"
+// (empty for percent-encoded text)
+func (header *DataHeader) Encoding() string {
+	return header.encoding
+}
+
+func (header *DataHeader) SetEncoding(encoding string) {
+	header.encoding = encoding
+}
+
+// Filename returns the "filename" parameter
+func (header *DataHeader) Filename() string {
+	return header.GetParameter("filename")
+}
+
+func (header *DataHeader) SetFilename(filename string) {
+	header.SetParameter("filename", filename)
+}
+
+// Charset returns the "charset" parameter
+func (header *DataHeader) Charset() string {
+	return header.GetParameter("charset")
+}
+
+// GetParameter returns the value of the parameter (names are case-insensitive)
+func (header *DataHeader) GetParameter(name string) string {
+	for _, item := range header.parameters {
+		if strings.EqualFold(item.Name, name) {
+			return item.Value
+		}
+	}
+	return ""
+}
+
+// SetParameter updates the parameter in place, or appends it if not exists;
+// an empty value removes the parameter
+func (header *DataHeader) SetParameter(name string, value string) {
+	for index, item := range header.parameters {
+		if !strings.EqualFold(item.Name, name) {
+			continue
+		} else if value == "" {
+			header.parameters = append(header.parameters[:index], header.parameters[index+1:]...)
+		} else {
+			header.parameters[index].Value = value
+		}
+		return
+	}
+	if value != "" {
+		header.parameters = append(header.parameters, DataParameter{Name: name, Value: value})
+	}
+}
+
+// Parameters returns a copy of all parameters in order
+func (header *DataHeader) Parameters() []DataParameter {
+	return append([]DataParameter{}, header.parameters...)
+}
+
+// String returns the header with the trailing comma, e.g. "data:image/png;base64,"
+func (header *DataHeader) String() string {
+	var sb strings.Builder
+	sb.WriteString("data:")
+	sb.WriteString(header.mimeType)
+	for _, item := range header.parameters {
+		sb.WriteByte(';')
+		sb.WriteString(escapeDataParameter(item.Name))
+		if item.Value != "" {
+			sb.WriteByte('=')
+			sb.WriteString(escapeDataParameter(item.Value))
+		}
+	}
+	if header.encoding != "" {
+		sb.WriteByte(';')
+		sb.WriteString(header.encoding)
+	}
+	sb.WriteByte(',')
+	return sb.String()
+}
+
+// ParseDataURI splits a data URI into header and (still encoded) data part
+//
+// Returns: nil header if the string is not a data URI
+func ParseDataURI(uri string) (*DataHeader, string) {
+	if len(uri) < 5 || !strings.EqualFold(uri[:5], "data:") {
+		return nil, ""
+	}
+	pos := strings.IndexByte(uri, ',')
+	if pos < 0 {
+		return nil, ""
+	}
+	segments := strings.Split(uri[5:pos], ";")
+	header := &DataHeader{
+		mimeType: strings.TrimSpace(segments[0]),
+	}
+	last := len(segments) - 1
+	for index := 1; index <= last; index++ {
+		segment := segments[index]
+		eq := strings.IndexByte(segment, '=')
+		if eq < 0 && index == last {
+			// encoding is the last segment without value
+			header.encoding = strings.ToLower(strings.TrimSpace(segment))
+			break
+		}
+		var name, value string
+		if eq < 0 {
+			name = segment
+		} else {
+			name = segment[:eq]
+			value = segment[eq+1:]
+		}
+		header.parameters = append(header.parameters, DataParameter{
+			Name:  unescapeDataParameter(strings.TrimSpace(name)),
+			Value: unescapeDataParameter(strings.TrimSpace(value)),
+		})
+	}
+	return header, uri[pos+1:]
+}
+
+// EncodeDataURIBody encodes binary data for the data part with the header's encoding
+//
+// Returns: encoded string, false if the encoding is not supported
+func EncodeDataURIBody(header *DataHeader, data []byte) (string, bool) {
+	if header.encoding == "" {
+		return url.PathEscape(string(data)), true
+	}
+	coder := GetDataCoder(header.encoding)
+	if coder == nil {
+		return "", false
+	}
+	return coder.Encode(data), true
+}
+
+// DecodeDataURIBody decodes the data part with the header's encoding
+//
+// Returns: binary data, nil if the encoding is not supported or the data is malformed
+func DecodeDataURIBody(header *DataHeader, body string) []byte {
+	if header.encoding == "" {
+		text, err := url.PathUnescape(body)
+		if err != nil {
+			return nil
+		}
+		return []byte(text)
+	}
+	coder := GetDataCoder(header.encoding)
+	if coder == nil {
+		return nil
+	}
+	return coder.Decode(body)
+}
+
+// characters must be escaped in header parameters
+func needsDataEscape(ch byte) bool {
+	return ch <= ' ' || ch >= 0x7F || strings.IndexByte(";,=%\"", ch) >= 0
+}
+
+func escapeDataParameter(str string) string {
+	var sb strings.Builder
+	for index := 0; index < len(str); index++ {
+		ch := str[index]
+		if needsDataEscape(ch) {
+			sb.WriteByte('%')
+			sb.WriteByte("0123456789ABCDEF"[ch>>4])
+			sb.WriteByte("0123456789ABCDEF"[ch&0xF])
+		} else {
+			sb.WriteByte(ch)
+		}
+	}
+	return sb.String()
+}
+
+func unescapeDataParameter(str string) string {
+	if strings.IndexByte(str, '%') < 0 {
+		return str
+	}
+	text, err := url.PathUnescape(str)
+	if err != nil {
+		// keep the original text
+		return str
+	}
+	return text
+}
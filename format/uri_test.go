@@ -0,0 +1,158 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package format
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+type testBase64Coder struct{}
+
+func (testBase64Coder) Encode(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func (testBase64Coder) Decode(str string) []byte {
+	data, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+type testHexCoder struct{}
+
+func (testHexCoder) Encode(data []byte) string {
+	return hex.EncodeToString(data)
+}
+
+func (testHexCoder) Decode(str string) []byte {
+	data, err := hex.DecodeString(str)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func init() {
+	SetBase64Coder(testBase64Coder{})
+	SetHexCoder(testHexCoder{})
+}
+
+func TestParseDataURI(t *testing.T) {
+	uri := "data:image/png;filename=my%20avatar.png;charset=UTF-8;base64,aGVsbG8="
+	header, body := ParseDataURI(uri)
+	if header == nil {
+		t.Fatalf("failed to parse data URI: %s", uri)
+	}
+	if header.MimeType() != "image/png" || header.Encoding() != "base64" {
+		t.Errorf("header: %s, %s", header.MimeType(), header.Encoding())
+	}
+	if header.Filename() != "my avatar.png" || header.Charset() != "UTF-8" {
+		t.Errorf("parameters: %q, %q", header.Filename(), header.Charset())
+	}
+	if body != "aGVsbG8=" {
+		t.Errorf("body: %s", body)
+	}
+	if header.String()+body != uri {
+		t.Errorf("round trip: %s", header.String()+body)
+	}
+	for _, str := range []string{"aGVsbG8=", "data:image/png;base64", ""} {
+		if header, _ := ParseDataURI(str); header != nil {
+			t.Errorf("not a data URI: %q", str)
+		}
+	}
+}
+
+func TestDataHeaderParameters(t *testing.T) {
+	header := NewDataHeader("text/plain", "")
+	header.SetFilename("a;b,c=d.txt")
+	header.SetParameter("charset", "utf-8")
+	header.SetParameter("Charset", "gbk")
+	if got := header.String(); got != "data:text/plain;filename=a%3Bb%2Cc%3Dd.txt;charset=gbk," {
+		t.Errorf("header string: %s", got)
+	}
+	header.SetParameter("CHARSET", "")
+	if len(header.Parameters()) != 1 {
+		t.Errorf("parameter not removed: %v", header.Parameters())
+	}
+	parsed, _ := ParseDataURI(header.String())
+	if parsed.Filename() != "a;b,c=d.txt" {
+		t.Errorf("filename: %q", parsed.Filename())
+	}
+}
+
+func TestEncodedDataFormats(t *testing.T) {
+	data := []byte("hello")
+	plain := NewEncodedData(data, nil)
+	if got := plain.String(); got != "aGVsbG8=" {
+		t.Errorf("plain base64: %s", got)
+	}
+	ted := NewEncodedData(data, NewDataHeader("text/plain", "hex"))
+	if got := ted.String(); got != "data:text/plain;hex,68656c6c6f" {
+		t.Errorf("hex data URI: %s", got)
+	}
+	raw := NewEncodedData([]byte("a b,c"), NewDataHeader("text/plain", ""))
+	if got := raw.String(); got != "data:text/plain,a%20b%2Cc" {
+		t.Errorf("raw data URI: %s", got)
+	}
+	for _, str := range []string{plain.String(), ted.String(), raw.String()} {
+		parsed := ParseEncodedData(str)
+		if parsed.String() != str {
+			t.Errorf("round trip: %s => %s", str, parsed.String())
+		}
+		if !parsed.Equal(str) {
+			t.Errorf("not equal: %s", str)
+		}
+	}
+	if !bytes.Equal(ParseEncodedData(ted.String()).Bytes(), data) {
+		t.Errorf("hex decode failed")
+	}
+	if got := NewEncodedData(data, NewDataHeader("", "unknown")).String(); got != "data:;unknown," {
+		t.Errorf("unknown encoding: %s", got)
+	}
+}
+
+func TestEncodedDataHeaderCopy(t *testing.T) {
+	header := NewDataHeader("image/png", "base64")
+	ted := NewEncodedData([]byte("hello"), header)
+	expected := ted.String()
+	// changing the header passed in or returned does not affect the TED
+	header.SetEncoding("hex")
+	ted.Header().SetEncoding("hex")
+	ted.Header().SetFilename("x.png")
+	if got := ted.String(); got != expected {
+		t.Errorf("TED changed by header: %s", got)
+	}
+	parsed := ParseEncodedData(expected)
+	parsed.Header().SetEncoding("hex")
+	if got := string(parsed.Bytes()); got != "hello" {
+		t.Errorf("parsed TED changed by header: %q", got)
+	}
+}
@@ -0,0 +1,207 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/dimchat/mkm-go/crypto"
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// DocumentBuilder creates, fills and signs a Document in one step
+//
+// Usage:
+//
+//	doc, err := NewDocumentBuilder(did, VISA, sKey).
+//	    SetName("Moky").
+//	    SetAvatar(avatar).
+//	    SetEncryptKey(visaKey).
+//	    WithMeta(meta).
+//	    Build()
+//
+// Errors in setters are kept and returned by Build().
+type DocumentBuilder struct {
+	did     ID
+	docType DocumentType
+	sKey    SignKey
+	meta    Meta
+
+	properties StringKeyMap
+	time       Time
+	notBefore  Time
+	expires    Time
+	prev       Document
+	err        error
+}
+
+func NewDocumentBuilder(did ID, docType DocumentType, sKey SignKey) *DocumentBuilder {
+	return &DocumentBuilder{
+		did:        did,
+		docType:    docType,
+		sKey:       sKey,
+		properties: NewMap(),
+		time:       TimeNil(),
+		notBefore:  TimeNil(),
+		expires:    TimeNil(),
+	}
+}
+
+// WithMeta sets the meta of the document owner (required)
+//
+// The meta must match the document ID, and the signed document is verified with meta.key
+func (builder *DocumentBuilder) WithMeta(meta Meta) *DocumentBuilder {
+	builder.meta = meta
+	return builder
+}
+
+// SetName sets the "name" property
+func (builder *DocumentBuilder) SetName(name string) *DocumentBuilder {
+	return builder.SetProperty("name", name)
+}
+
+// SetAvatar sets the "avatar" property with PNF
+func (builder *DocumentBuilder) SetAvatar(avatar TransportableFile) *DocumentBuilder {
+	if avatar == nil {
+		return builder.SetProperty("avatar", nil)
+	}
+	return builder.SetProperty("avatar", avatar.Serialize())
+}
+
+// SetEncryptKey sets the "key" property with public key for encryption (visa only)
+func (builder *DocumentBuilder) SetEncryptKey(key EncryptKey) *DocumentBuilder {
+	if key == nil {
+		return builder.SetProperty("key", nil)
+	}
+	return builder.SetProperty("key", key.Map())
+}
+
+// SetTime sets the "time" property (default: now)
+func (builder *DocumentBuilder) SetTime(time Time) *DocumentBuilder {
+	builder.time = time
+	return builder
+}
+
+// SetValidity sets the "not_before" and "expires" properties (nil time to skip)
+func (builder *DocumentBuilder) SetValidity(notBefore, expires Time) *DocumentBuilder {
+	if !TimeIsNil(notBefore) && !TimeIsNil(expires) && notBefore.UnixNano() >= expires.UnixNano() {
+		builder.fail(errors.New("document validity window empty"))
+	}
+	builder.notBefore = notBefore
+	builder.expires = expires
+	return builder
+}
+
+// SetPrevious links the new document to the previous one with "prev" property (nil to unlink)
+//
+// The previous document must be signed, see SetPreviousDocument()
+func (builder *DocumentBuilder) SetPrevious(prev Document) *DocumentBuilder {
+	builder.prev = prev
+	if prev == nil {
+		return builder.SetProperty("prev", nil)
+	}
+	return builder
+}
+
+// SetProperty sets a custom property, nil value removes it
+func (builder *DocumentBuilder) SetProperty(name string, value any) *DocumentBuilder {
+	if name == "" {
+		builder.fail(errors.New("document property name empty"))
+	} else if value == nil {
+		delete(builder.properties, name)
+	} else {
+		builder.properties[name] = value
+	}
+	return builder
+}
+
+func (builder *DocumentBuilder) fail(err error) {
+	if builder.err == nil {
+		builder.err = err
+	}
+}
+
+// Build creates the document, signs it with sKey and verifies the signature
+//
+// Returns: signed document, or error describing the failed step
+func (builder *DocumentBuilder) Build() (Document, error) {
+	if builder.err != nil {
+		return nil, builder.err
+	} else if builder.did == nil {
+		return nil, errors.New("document ID not provided")
+	} else if builder.docType == "" {
+		return nil, errors.New("document type not provided")
+	} else if builder.sKey == nil {
+		return nil, errors.New("sign key not provided")
+	}
+	metaKey, err := builder.verifyKey()
+	if err != nil {
+		return nil, err
+	}
+	if GetDocumentHelper() == nil {
+		return nil, errors.New("document helper not set")
+	}
+	doc := CreateDocument(builder.docType, "", nil)
+	if doc == nil {
+		return nil, fmt.Errorf("failed to create document: %s", builder.docType)
+	}
+	doc.Set("did", builder.did.String())
+	for name, value := range builder.properties {
+		doc.SetProperty(name, value)
+	}
+	when := builder.time
+	if TimeIsNil(when) {
+		when = TimeNow()
+	}
+	doc.SetProperty("time", TimeToFloat64(when))
+	if !TimeIsNil(builder.notBefore) || !TimeIsNil(builder.expires) {
+		SetDocumentValidity(doc, builder.notBefore, builder.expires)
+	}
+	if builder.prev != nil {
+		if err = SetPreviousDocument(doc, builder.prev); err != nil {
+			return nil, err
+		}
+	}
+	if signature := doc.Sign(builder.sKey); len(signature) == 0 {
+		return nil, fmt.Errorf("failed to sign document: %s", builder.did.String())
+	} else if !doc.Verify(metaKey) {
+		return nil, fmt.Errorf("document signature not match meta key: %s", builder.did.String())
+	}
+	return doc, nil
+}
+
+// verifyKey returns the public key to verify the signed document
+func (builder *DocumentBuilder) verifyKey() (VerifyKey, error) {
+	meta := builder.meta
+	if meta == nil {
+		return nil, errors.New("meta not provided for verifying document")
+	} else if !MetaMatchID(meta, builder.did) {
+		return nil, fmt.Errorf("meta not match document ID: %s", builder.did.String())
+	}
+	return meta.PublicKey(), nil
+}
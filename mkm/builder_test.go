@@ -0,0 +1,144 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"testing"
+
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func TestMetaMatchID(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	if !MetaMatchID(meta, did) {
+		t.Fatalf("meta not match its ID")
+	}
+	if !MetaMatchID(meta, meta.ID("phone")) {
+		t.Errorf("meta not match terminal ID")
+	}
+	other := newTestMeta(newTestKey("evil-key"), "moky")
+	if MetaMatchID(other, did) {
+		t.Errorf("meta of another key matches")
+	}
+	renamed := NewID("hulk", did.Address(), "")
+	if MetaMatchID(meta, renamed) {
+		t.Errorf("meta matches ID with another name")
+	}
+	invalid := newTestMeta(key, "moky")
+	invalid.valid = false
+	if MetaMatchID(invalid, did) {
+		t.Errorf("invalid meta matches")
+	}
+	if MetaMatchID(nil, did) || MetaMatchID(meta, nil) {
+		t.Errorf("nil matches")
+	}
+}
+
+func TestDocumentBuilder(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	doc, err := NewDocumentBuilder(did, VISA, key).
+		WithMeta(meta).
+		SetName("Moky").
+		SetTime(TimeFromFloat64(1700000000)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !doc.Verify(meta.PublicKey()) {
+		t.Errorf("document not verified with meta key")
+	}
+	if doc.GetProperty("name") != "Moky" || TimeToFloat64(doc.Time()) != 1700000000 {
+		t.Errorf("properties: %v", doc.Properties())
+	}
+	if documentID(doc).String() != did.String() || documentType(doc) != VISA {
+		t.Errorf("document ID or type not match")
+	}
+}
+
+func TestDocumentBuilderRejects(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	evilKey := newTestKey("evil-key")
+	evilMeta := newTestMeta(evilKey, "moky")
+	cases := []struct {
+		name    string
+		builder *DocumentBuilder
+	}{
+		{"no meta", NewDocumentBuilder(did, VISA, key)},
+		{"meta of another entity", NewDocumentBuilder(did, VISA, evilKey).WithMeta(evilMeta)},
+		{"key not match meta", NewDocumentBuilder(did, VISA, evilKey).WithMeta(meta)},
+		{"no ID", NewDocumentBuilder(nil, VISA, key).WithMeta(meta)},
+		{"no type", NewDocumentBuilder(did, "", key).WithMeta(meta)},
+		{"no key", NewDocumentBuilder(did, VISA, nil).WithMeta(meta)},
+		{"empty validity", NewDocumentBuilder(did, VISA, key).WithMeta(meta).
+			SetValidity(TimeFromFloat64(200), TimeFromFloat64(100))},
+		{"empty property name", NewDocumentBuilder(did, VISA, key).WithMeta(meta).SetProperty("", 1)},
+	}
+	for _, item := range cases {
+		if doc, err := item.builder.Build(); err == nil || doc != nil {
+			t.Errorf("%s: expected error", item.name)
+		}
+	}
+}
+
+func TestDocumentBuilderPrevious(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	genesis := signNextDocument(did, key, 1000, nil, nil)
+	doc, err := NewDocumentBuilder(did, VISA, key).
+		WithMeta(meta).
+		SetTime(TimeFromFloat64(2000)).
+		SetPrevious(genesis).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = VerifyDocumentChain(meta, did, []Document{genesis, doc}); err != nil {
+		t.Error(err)
+	}
+	// unlink
+	doc, err = NewDocumentBuilder(did, VISA, key).WithMeta(meta).
+		SetProperty("prev", "sha256:00").
+		SetPrevious(genesis).
+		SetPrevious(nil).
+		Build()
+	if err != nil || DocumentPrevious(doc) != "" {
+		t.Errorf("prev not removed: %q, %v", DocumentPrevious(doc), err)
+	}
+	// previous document not signed
+	unsigned := newTestDocument(VISA)
+	unsigned.Set("did", did.String())
+	if _, err = NewDocumentBuilder(did, VISA, key).WithMeta(meta).SetPrevious(unsigned).Build(); err == nil {
+		t.Errorf("linked to unsigned document")
+	}
+}
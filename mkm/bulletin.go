@@ -0,0 +1,204 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// BaseBulletin wraps a group document with typed accessors
+type BaseBulletin struct {
+	//Bulletin
+	Document
+}
+
+func NewBaseBulletin(doc Document) *BaseBulletin {
+	return &BaseBulletin{
+		Document: doc,
+	}
+}
+
+// BulletinFromDocument returns the document as Bulletin (wrapped if needed)
+func BulletinFromDocument(doc Document) Bulletin {
+	if doc == nil {
+		return nil
+	} else if bulletin, ok := doc.(Bulletin); ok {
+		return bulletin
+	}
+	return NewBaseBulletin(doc)
+}
+
+//-------- Bulletin
+
+// Override
+func (bulletin *BaseBulletin) Name() string {
+	return documentString(bulletin, "name")
+}
+
+// Override
+func (bulletin *BaseBulletin) SetName(name string) {
+	bulletin.SetProperty("name", name)
+}
+
+// Override
+func (bulletin *BaseBulletin) Founder() ID {
+	return ParseID(bulletin.GetProperty("founder"))
+}
+
+// Override
+func (bulletin *BaseBulletin) SetFounder(founder ID) {
+	bulletin.setID("founder", founder)
+}
+
+// Override
+func (bulletin *BaseBulletin) Owner() ID {
+	owner := ParseID(bulletin.GetProperty("owner"))
+	if owner == nil {
+		return bulletin.Founder()
+	}
+	return owner
+}
+
+// Override
+func (bulletin *BaseBulletin) SetOwner(owner ID) {
+	bulletin.setID("owner", owner)
+}
+
+// Override
+func (bulletin *BaseBulletin) Administrators() []ID {
+	return bulletin.getIDList("administrators")
+}
+
+// Override
+func (bulletin *BaseBulletin) SetAdministrators(admins []ID) {
+	bulletin.setIDList("administrators", admins)
+}
+
+// Override
+func (bulletin *BaseBulletin) Assistants() []ID {
+	return bulletin.getIDList("assistants")
+}
+
+// Override
+func (bulletin *BaseBulletin) SetAssistants(bots []ID) {
+	bulletin.setIDList("assistants", bots)
+}
+
+// IsExpired checks the "expires" property
+func (bulletin *BaseBulletin) IsExpired(now Time) bool {
+	return DocumentIsExpired(bulletin, now)
+}
+
+func (bulletin *BaseBulletin) setID(name string, did ID) {
+	if did == nil {
+		bulletin.SetProperty(name, nil)
+	} else {
+		bulletin.SetProperty(name, did.String())
+	}
+}
+
+func (bulletin *BaseBulletin) getIDList(name string) []ID {
+	array := bulletin.GetProperty(name)
+	if array == nil {
+		return nil
+	}
+	return IDConvert(array)
+}
+
+func (bulletin *BaseBulletin) setIDList(name string, members []ID) {
+	if len(members) == 0 {
+		bulletin.SetProperty(name, nil)
+	} else {
+		bulletin.SetProperty(name, IDRevert(members))
+	}
+}
+
+// CheckBulletin validates the member roles in group document
+//
+//	Rules:
+//	    1. founder must exist and be a user ID;
+//	    2. owner, administrators and assistants (if exist) must be user IDs;
+//	    3. no duplicated administrators or assistants.
+//
+// The raw property values are checked, so malformed entries (which the accessors
+// skip, or replace with the founder) are reported instead of being ignored.
+//
+// Returns: nil if the bulletin is valid, otherwise error describing the first problem
+func CheckBulletin(bulletin Bulletin) error {
+	if bulletin == nil {
+		return errors.New("bulletin not provided")
+	}
+	value := bulletin.GetProperty("founder")
+	if value == nil {
+		return errors.New("group founder not found")
+	}
+	founder := ParseID(value)
+	if founder == nil {
+		return fmt.Errorf("group founder is not an ID: %v", value)
+	} else if !founder.IsUser() {
+		return fmt.Errorf("group founder is not a user: %s", founder.String())
+	}
+	if value = bulletin.GetProperty("owner"); value != nil {
+		if owner := ParseID(value); owner == nil {
+			return fmt.Errorf("group owner is not an ID: %v", value)
+		} else if !owner.IsUser() {
+			return fmt.Errorf("group owner is not a user: %s", owner.String())
+		}
+	}
+	if err := checkUserList("administrator", bulletin.GetProperty("administrators")); err != nil {
+		return err
+	}
+	return checkUserList("assistant", bulletin.GetProperty("assistants"))
+}
+
+func checkUserList(role string, value any) error {
+	if value == nil {
+		return nil
+	}
+	array := FetchList(value)
+	if array == nil {
+		return fmt.Errorf("group %ss is not a list: %v", role, value)
+	}
+	exists := make(map[string]bool, len(array))
+	for _, item := range array {
+		member := ParseID(item)
+		if member == nil {
+			return fmt.Errorf("group %s is not an ID: %v", role, item)
+		}
+		str := member.String()
+		if !member.IsUser() {
+			return fmt.Errorf("group %s is not a user: %s", role, str)
+		} else if exists[str] {
+			return fmt.Errorf("duplicated group %s: %s", role, str)
+		}
+		exists[str] = true
+	}
+	return nil
+}
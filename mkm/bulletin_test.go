@@ -0,0 +1,149 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"testing"
+
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func testUserID(name string) ID {
+	return newTestMeta(newTestKey(name+"-key"), name).ID("")
+}
+
+func testGroupID(name string) ID {
+	return NewID(name, newTestAddress("group-"+name, GROUP), "")
+}
+
+func TestBulletinAccessors(t *testing.T) {
+	if BulletinFromDocument(nil) != nil {
+		t.Errorf("bulletin from nil document")
+	}
+	doc := newTestDocument(BULLETIN)
+	bulletin := BulletinFromDocument(doc)
+	if BulletinFromDocument(bulletin) != bulletin {
+		t.Errorf("bulletin wrapped again")
+	}
+	founder, owner := testUserID("founder"), testUserID("owner")
+	admins := []ID{testUserID("alice"), testUserID("bob")}
+	bots := []ID{testUserID("bot")}
+	bulletin.SetName("Group")
+	bulletin.SetFounder(founder)
+	// owner defaults to founder
+	if got := bulletin.Owner(); got == nil || got.String() != founder.String() {
+		t.Errorf("owner: %v", got)
+	}
+	bulletin.SetOwner(owner)
+	bulletin.SetAdministrators(admins)
+	bulletin.SetAssistants(bots)
+
+	// stored as strings
+	stored := doc.GetProperty("administrators")
+	if list, ok := stored.([]string); !ok || len(list) != 2 || list[0] != admins[0].String() {
+		t.Fatalf("administrators: %#v", stored)
+	}
+	if doc.GetProperty("founder") != founder.String() {
+		t.Errorf("founder: %#v", doc.GetProperty("founder"))
+	}
+	if bulletin.Name() != "Group" || bulletin.Founder().String() != founder.String() ||
+		bulletin.Owner().String() != owner.String() {
+		t.Errorf("bulletin: %v", doc.Properties())
+	}
+	got := bulletin.Administrators()
+	if len(got) != 2 || got[0].String() != admins[0].String() || got[1].String() != admins[1].String() {
+		t.Errorf("administrators: %v", got)
+	}
+	if got = bulletin.Assistants(); len(got) != 1 || got[0].String() != bots[0].String() {
+		t.Errorf("assistants: %v", got)
+	}
+	// IDRevert/IDConvert round trip
+	if got = IDConvert(IDRevert(admins)); len(got) != 2 || got[1].String() != admins[1].String() {
+		t.Errorf("IDConvert(IDRevert()): %v", got)
+	}
+	// removed
+	bulletin.SetOwner(nil)
+	bulletin.SetAdministrators(nil)
+	bulletin.SetAssistants([]ID{})
+	if doc.GetProperty("owner") != nil || doc.GetProperty("administrators") != nil ||
+		doc.GetProperty("assistants") != nil {
+		t.Errorf("properties not removed: %v", doc.Properties())
+	}
+	if bulletin.Administrators() != nil || bulletin.Assistants() != nil {
+		t.Errorf("lists not removed")
+	}
+	if err := CheckBulletin(bulletin); err != nil {
+		t.Errorf("valid bulletin: %v", err)
+	}
+}
+
+func TestCheckBulletin(t *testing.T) {
+	founder := testUserID("founder").String()
+	alice := testUserID("alice").String()
+	group := testGroupID("group").String()
+	cases := []struct {
+		name  string
+		props StringKeyMap
+		valid bool
+	}{
+		{"founder only", StringKeyMap{"founder": founder}, true},
+		{"all roles", StringKeyMap{
+			"founder":        founder,
+			"owner":          alice,
+			"administrators": []any{alice},
+			"assistants":     []any{founder},
+		}, true},
+		{"empty lists", StringKeyMap{"founder": founder, "administrators": []any{}}, true},
+		{"no founder", StringKeyMap{"owner": alice}, false},
+		{"malformed founder", StringKeyMap{"founder": "garbage"}, false},
+		{"group founder", StringKeyMap{"founder": group}, false},
+		{"malformed owner", StringKeyMap{"founder": founder, "owner": 42}, false},
+		{"group owner", StringKeyMap{"founder": founder, "owner": group}, false},
+		{"malformed administrator", StringKeyMap{"founder": founder, "administrators": []any{alice, "garbage"}}, false},
+		{"group administrator", StringKeyMap{"founder": founder, "administrators": []any{group}}, false},
+		{"duplicated administrator", StringKeyMap{"founder": founder, "administrators": []any{alice, alice}}, false},
+		{"administrators not a list", StringKeyMap{"founder": founder, "administrators": alice}, false},
+		{"malformed assistant", StringKeyMap{"founder": founder, "assistants": []any{nil}}, false},
+		{"group assistant", StringKeyMap{"founder": founder, "assistants": []any{group}}, false},
+		{"duplicated assistant", StringKeyMap{"founder": founder, "assistants": []any{alice, alice}}, false},
+	}
+	for _, item := range cases {
+		doc := newTestDocument(BULLETIN)
+		for name, value := range item.props {
+			doc.SetProperty(name, value)
+		}
+		err := CheckBulletin(BulletinFromDocument(doc))
+		if item.valid && err != nil {
+			t.Errorf("%s: %v", item.name, err)
+		} else if !item.valid && err == nil {
+			t.Errorf("%s: expected error", item.name)
+		}
+	}
+	if err := CheckBulletin(nil); err == nil {
+		t.Errorf("nil bulletin: expected error")
+	}
+}
@@ -0,0 +1,204 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/dimchat/mkm-go/digest"
+	. "github.com/dimchat/mkm-go/format"
+	. "github.com/dimchat/mkm-go/protocol"
+)
+
+/**
+ *  Document History Chain
+ *
+ *      info: {
+ *          ...
+ *          "prev" : "sha256:{HEX}"  // OPTIONAL, digest of the previous document
+ *      }
+ *
+ *      digest = sha256(utf8_encode(data) + signature)
+ *
+ *  The first document (genesis) has no "prev"; as "prev" is a property,
+ *  it's covered by the signature.
+ */
+
+// DocumentDigest returns the digest of the signed document (empty if not signed)
+func DocumentDigest(doc Document) string {
+	data := doc.GetString("data", "")
+	ted := ParseTransportableData(doc.Get("signature"))
+	if data == "" || ted == nil {
+		return ""
+	}
+	signature := ted.Bytes()
+	if len(signature) == 0 {
+		return ""
+	}
+	buffer := append(UTF8Encode(data), signature...)
+	return ContentDigest("sha256", buffer)
+}
+
+// DocumentPrevious returns the "prev" property (empty for genesis)
+func DocumentPrevious(doc Document) string {
+	return documentString(doc, "prev")
+}
+
+// SetPreviousDocument links the document to the previous one (needs to sign again)
+func SetPreviousDocument(doc Document, prev Document) error {
+	if prev == nil {
+		doc.SetProperty("prev", nil)
+		return nil
+	}
+	digest := DocumentDigest(prev)
+	if digest == "" {
+		return errors.New("previous document not signed")
+	}
+	doc.SetProperty("prev", digest)
+	return nil
+}
+
+// DocumentFork describes documents claiming the same predecessor
+type DocumentFork struct {
+	Previous  string // digest of the common predecessor (empty for genesis)
+	Documents []Document
+}
+
+// FindDocumentForks returns all forks in the documents
+//
+// The same signed document received more than once is not a fork.
+func FindDocumentForks(documents []Document) []DocumentFork {
+	children := map[string][]Document{}
+	var order []string
+	for _, doc := range uniqueDocuments(documents) {
+		prev := DocumentPrevious(doc)
+		if _, exists := children[prev]; !exists {
+			order = append(order, prev)
+		}
+		children[prev] = append(children[prev], doc)
+	}
+	var forks []DocumentFork
+	for _, prev := range order {
+		if len(children[prev]) > 1 {
+			forks = append(forks, DocumentFork{prev, children[prev]})
+		}
+	}
+	return forks
+}
+
+// BuildDocumentChain orders documents of one ID from genesis to the newest by "prev" links
+//
+// Returns: error if there is a fork, a missing link, or unlinked documents
+func BuildDocumentChain(documents []Document) ([]Document, error) {
+	documents = uniqueDocuments(documents)
+	if len(documents) == 0 {
+		return nil, errors.New("documents empty")
+	} else if forks := FindDocumentForks(documents); len(forks) > 0 {
+		return nil, fmt.Errorf("document chain forked at %q (%d branches)", forks[0].Previous, len(forks[0].Documents))
+	}
+	next := map[string]Document{}
+	for _, doc := range documents {
+		next[DocumentPrevious(doc)] = doc
+	}
+	chain := make([]Document, 0, len(documents))
+	current := next[""]
+	if current == nil {
+		return nil, errors.New("genesis document not found")
+	}
+	for current != nil && len(chain) < len(documents) {
+		chain = append(chain, current)
+		digest := DocumentDigest(current)
+		if digest == "" {
+			return nil, errors.New("document not signed")
+		}
+		current = next[digest]
+	}
+	if len(chain) != len(documents) {
+		return nil, fmt.Errorf("document chain broken: %d of %d documents linked", len(chain), len(documents))
+	}
+	return chain, nil
+}
+
+// uniqueDocuments removes duplicated documents with the same digest (keeps the first one);
+// unsigned documents have no digest, they are all kept
+func uniqueDocuments(documents []Document) []Document {
+	unique := make([]Document, 0, len(documents))
+	exists := make(map[string]bool, len(documents))
+	for _, doc := range documents {
+		if digest := DocumentDigest(doc); digest != "" {
+			if exists[digest] {
+				continue
+			}
+			exists[digest] = true
+		}
+		unique = append(unique, doc)
+	}
+	return unique
+}
+
+// VerifyDocumentChain checks an ordered chain (genesis first)
+//
+//	Rules:
+//	    0. meta must match the ID;
+//	    1. every document is owned by the ID and signed by meta.key;
+//	    2. the first document has no "prev";
+//	    3. every other "prev" matches the digest of the document before it;
+//	    4. document time is not decreasing.
+func VerifyDocumentChain(meta Meta, did ID, chain []Document) error {
+	if did == nil {
+		return errors.New("entity ID not provided")
+	} else if meta == nil {
+		return errors.New("meta not provided")
+	} else if !MetaMatchID(meta, did) {
+		return fmt.Errorf("meta not match ID: %s", did.String())
+	}
+	var prevDigest string
+	var prevTime int64
+	for index, doc := range chain {
+		if doc == nil {
+			return fmt.Errorf("document #%d empty", index)
+		}
+		owner := documentID(doc)
+		if owner == nil || !sameEntity(owner, did) || owner.Terminal() != did.Terminal() {
+			return fmt.Errorf("document #%d ID not match: %s", index, did.String())
+		} else if !doc.Verify(meta.PublicKey()) {
+			return fmt.Errorf("document #%d signature not match: %s", index, did.String())
+		} else if prev := DocumentPrevious(doc); prev != prevDigest {
+			return fmt.Errorf("document #%d prev not match: %q, expected %q", index, prev, prevDigest)
+		}
+		when := documentTime(doc)
+		if when < prevTime {
+			return fmt.Errorf("document #%d time earlier than previous one", index)
+		}
+		prevTime = when
+		prevDigest = DocumentDigest(doc)
+		if prevDigest == "" {
+			return fmt.Errorf("document #%d not signed", index)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"testing"
+
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// signNextDocument signs a document linked to the previous one
+func signNextDocument(did ID, key *testKey, when float64, prev Document, properties StringKeyMap) Document {
+	doc := newTestDocument(VISA)
+	doc.Set("did", did.String())
+	for name, value := range properties {
+		doc.SetProperty(name, value)
+	}
+	doc.SetProperty("time", when)
+	if err := SetPreviousDocument(doc, prev); err != nil {
+		panic(err)
+	}
+	doc.Sign(key)
+	return doc
+}
+
+func TestDocumentChain(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	genesis := signNextDocument(did, key, 1000, nil, StringKeyMap{"name": "v1"})
+	second := signNextDocument(did, key, 2000, genesis, StringKeyMap{"name": "v2"})
+	third := signNextDocument(did, key, 3000, second, StringKeyMap{"name": "v3"})
+	if DocumentPrevious(genesis) != "" || DocumentPrevious(second) != DocumentDigest(genesis) {
+		t.Fatalf("prev not linked: %q", DocumentPrevious(second))
+	}
+	chain, err := BuildDocumentChain([]Document{third, genesis, second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != 3 || chain[0] != genesis || chain[1] != second || chain[2] != third {
+		t.Fatalf("chain: %v", chain)
+	}
+	if err = VerifyDocumentChain(meta, did, chain); err != nil {
+		t.Fatal(err)
+	}
+	// signature swapped from another document
+	second.Set("signature", third.Get("signature"))
+	if err = VerifyDocumentChain(meta, did, chain); err == nil {
+		t.Errorf("tampered chain verified")
+	}
+}
+
+func TestDocumentChainErrors(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	genesis := signNextDocument(did, key, 1000, nil, nil)
+	second := signNextDocument(did, key, 2000, genesis, StringKeyMap{"name": "a"})
+	branch := signNextDocument(did, key, 2500, genesis, StringKeyMap{"name": "b"})
+	third := signNextDocument(did, key, 3000, second, nil)
+	forks := FindDocumentForks([]Document{genesis, second, branch, third})
+	if len(forks) != 1 || forks[0].Previous != DocumentDigest(genesis) || len(forks[0].Documents) != 2 {
+		t.Fatalf("forks: %v", forks)
+	}
+	if _, err := BuildDocumentChain([]Document{genesis, second, branch}); err == nil {
+		t.Errorf("forked chain built")
+	}
+	if _, err := BuildDocumentChain([]Document{genesis, third}); err == nil {
+		t.Errorf("broken chain built")
+	}
+	if _, err := BuildDocumentChain([]Document{second, third}); err == nil {
+		t.Errorf("chain without genesis built")
+	}
+	if _, err := BuildDocumentChain(nil); err == nil {
+		t.Errorf("empty chain built")
+	}
+	// verify rules
+	evilMeta := newTestMeta(newTestKey("evil-key"), "moky")
+	cases := []struct {
+		name  string
+		meta  Meta
+		did   ID
+		chain []Document
+	}{
+		{"nil ID", meta, nil, []Document{genesis}},
+		{"nil meta", nil, did, []Document{genesis}},
+		{"foreign meta", evilMeta, did, []Document{genesis}},
+		{"nil document", meta, did, []Document{genesis, nil}},
+		{"genesis with prev", meta, did, []Document{second, third}},
+		{"missing link", meta, did, []Document{genesis, third}},
+		{"time decreasing", meta, did, []Document{genesis, signNextDocument(did, key, 500, genesis, nil)}},
+		{"other terminal", meta, meta.ID("phone"), []Document{genesis}},
+	}
+	for _, item := range cases {
+		if err := VerifyDocumentChain(item.meta, item.did, item.chain); err == nil {
+			t.Errorf("%s: expected error", item.name)
+		}
+	}
+}
+
+func TestMergeContinuesChain(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	genesis := signNextDocument(did, key, 1000, nil, StringKeyMap{"name": "Moky"})
+	ours := signNextDocument(did, key, 2000, genesis, StringKeyMap{"name": "Albert"})
+	theirs := signNextDocument(did, key, 2500, genesis, StringKeyMap{"name": "Moky", "avatar": "a.png"})
+	merged, conflicts, err := MergeDocuments(genesis, ours, theirs)
+	if err != nil || len(conflicts) != 0 {
+		t.Fatalf("merge: %v, %v", err, conflicts)
+	}
+	// "prev" of the merged document points to ours, not copied from either side
+	if DocumentPrevious(merged) != DocumentDigest(ours) {
+		t.Fatalf("merged prev: %q", DocumentPrevious(merged))
+	}
+	merged.Sign(key)
+	documents := []Document{genesis, ours, merged}
+	if forks := FindDocumentForks(documents); len(forks) != 0 {
+		t.Errorf("merged document forks: %v", forks)
+	}
+	chain, err := BuildDocumentChain(documents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = VerifyDocumentChain(meta, did, chain); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDocumentChainDuplicates(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	genesis := signNextDocument(did, key, 1000, nil, StringKeyMap{"name": "v1"})
+	second := signNextDocument(did, key, 2000, genesis, StringKeyMap{"name": "v2"})
+	// the same document pushed twice, one of them parsed again from its map
+	copied := newTestDocument(VISA)
+	for name, value := range second.Map() {
+		copied.Set(name, value)
+	}
+	documents := []Document{genesis, second, copied, genesis}
+	if forks := FindDocumentForks(documents); len(forks) != 0 {
+		t.Errorf("duplicates reported as fork: %v", forks)
+	}
+	chain, err := BuildDocumentChain(documents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != 2 || chain[0] != genesis || chain[1] != second {
+		t.Errorf("chain: %v", chain)
+	}
+}
@@ -0,0 +1,463 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+
+	. "github.com/dimchat/mkm-go/crypto"
+	. "github.com/dimchat/mkm-go/format"
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+/**
+ *  Test fixtures: keys, meta, ID and document implementations
+ */
+
+type testBase64Coder struct{}
+
+func (testBase64Coder) Encode(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func (testBase64Coder) Decode(str string) []byte {
+	data, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func init() {
+	SetBase64Coder(testBase64Coder{})
+	SetTransportableDataHelper(testTEDHelper{})
+	SetIDHelper(testIDHelper{})
+	SetDocumentHelper(testDocumentHelper{})
+	SetDocumentDataCoder(CanonicalJSONCoder{})
+	SetPublicKeyHelper(testPublicKeyHelper{})
+	SetTransportableFileHelper(testFileHelper{})
+}
+
+type testTEDHelper struct{}
+
+func (testTEDHelper) SetTransportableDataFactory(factory TransportableDataFactory) {}
+
+func (testTEDHelper) GetTransportableDataFactory() TransportableDataFactory {
+	return EncodedDataFactory{}
+}
+
+func (testTEDHelper) ParseTransportableData(ted any) TransportableData {
+	if str, ok := ted.(string); ok {
+		return EncodedDataFactory{}.ParseTransportableData(str)
+	}
+	return nil
+}
+
+// testKey signs with a keyed hash, the same instance is used as private and public key
+type testKey struct {
+	*Dictionary
+
+	name string
+}
+
+func newTestKey(name string) *testKey {
+	return &testKey{
+		Dictionary: NewDictionary(StringKeyMap{"algorithm": "TEST", "name": name}),
+		name:       name,
+	}
+}
+
+// Override
+func (key *testKey) Algorithm() string {
+	return "TEST"
+}
+
+// Override
+func (key *testKey) Data() TransportableData {
+	return NewEncodedData([]byte(key.name), nil)
+}
+
+// Override
+func (key *testKey) Sign(data []byte) []byte {
+	hash := sha256.Sum256(append([]byte(key.name+":"), data...))
+	return hash[:]
+}
+
+// Override
+func (key *testKey) Verify(data []byte, signature []byte) bool {
+	return string(key.Sign(data)) == string(signature)
+}
+
+// Override
+func (key *testKey) MatchSignKey(sKey SignKey) bool {
+	other, ok := sKey.(*testKey)
+	return ok && other.name == key.name
+}
+
+type testAddress struct {
+	ConstantString
+
+	network EntityType
+}
+
+func newTestAddress(str string, network EntityType) *testAddress {
+	return &testAddress{
+		ConstantString: *NewConstantString(str),
+		network:        network,
+	}
+}
+
+// Override
+func (address *testAddress) Network() EntityType {
+	return address.network
+}
+
+// testMeta generates address from key name and seed
+type testMeta struct {
+	*Dictionary
+
+	key   *testKey
+	seed  string
+	valid bool
+}
+
+func newTestMeta(key *testKey, seed string) *testMeta {
+	return &testMeta{
+		Dictionary: NewDictionary(StringKeyMap{"type": "1", "seed": seed}),
+		key:        key,
+		seed:       seed,
+		valid:      true,
+	}
+}
+
+// Override
+func (meta *testMeta) Type() MetaType {
+	return "1"
+}
+
+// Override
+func (meta *testMeta) PublicKey() VerifyKey {
+	return meta.key
+}
+
+// Override
+func (meta *testMeta) Seed() string {
+	return meta.seed
+}
+
+// Override
+func (meta *testMeta) Fingerprint() TransportableData {
+	return NewEncodedData(meta.key.Sign([]byte(meta.seed)), nil)
+}
+
+// Override
+func (meta *testMeta) IsValid() bool {
+	return meta.valid
+}
+
+// Override
+func (meta *testMeta) GenerateAddress(network EntityType) Address {
+	hash := sha256.Sum256([]byte(meta.key.name + "/" + meta.seed))
+	return newTestAddress(hex.EncodeToString(hash[:8]), network)
+}
+
+// ID of the meta
+func (meta *testMeta) ID(terminal string) ID {
+	return NewID(meta.seed, meta.GenerateAddress(USER), terminal)
+}
+
+// testIDHelper parses "name@address/terminal", addresses with "group-" prefix are groups
+type testIDHelper struct{}
+
+func (testIDHelper) SetIDFactory(factory IDFactory) {}
+
+func (testIDHelper) GetIDFactory() IDFactory {
+	return nil
+}
+
+func (testIDHelper) ParseID(did any) ID {
+	if did == nil {
+		return nil
+	} else if v, ok := did.(ID); ok {
+		return v
+	}
+	str := FetchString(did)
+	var name, terminal string
+	if pos := strings.IndexByte(str, '/'); pos >= 0 {
+		str, terminal = str[:pos], str[pos+1:]
+	}
+	pos := strings.IndexByte(str, '@')
+	if pos < 0 {
+		return nil
+	}
+	name, str = str[:pos], str[pos+1:]
+	if str == "" {
+		return nil
+	}
+	network := USER
+	if strings.HasPrefix(str, "group-") {
+		network = GROUP
+	}
+	return NewID(name, newTestAddress(str, network), terminal)
+}
+
+func (testIDHelper) CreateID(name string, address Address, terminal string) ID {
+	return NewID(name, address, terminal)
+}
+
+func (testIDHelper) GenerateID(meta Meta, network EntityType, terminal string) ID {
+	return NewID(meta.Seed(), meta.GenerateAddress(network), terminal)
+}
+
+// testDocument keeps properties in "data" after signed
+type testDocument struct {
+	*Dictionary
+
+	properties StringKeyMap
+}
+
+func newTestDocument(docType DocumentType) *testDocument {
+	doc := &testDocument{
+		Dictionary: NewDictionary(nil),
+	}
+	if docType != "" {
+		doc.Set("type", docType)
+	}
+	return doc
+}
+
+// Override
+func (doc *testDocument) IsValid() bool {
+	return doc.GetString("signature", "") != ""
+}
+
+// Override
+func (doc *testDocument) Verify(metaKey VerifyKey) bool {
+	data := doc.GetString("data", "")
+	ted := ParseTransportableData(doc.Get("signature"))
+	if metaKey == nil || data == "" || ted == nil {
+		return false
+	}
+	return metaKey.Verify(UTF8Encode(data), ted.Bytes())
+}
+
+// Override
+func (doc *testDocument) Sign(sKey SignKey) []byte {
+	data, signature := SignDocumentData(doc.Properties(), sKey)
+	if len(signature) == 0 {
+		return nil
+	}
+	doc.Set("data", data)
+	doc.Set("signature", base64.StdEncoding.EncodeToString(signature))
+	return signature
+}
+
+// Override
+func (doc *testDocument) Properties() StringKeyMap {
+	if doc.properties == nil {
+		if data := doc.GetString("data", ""); data != "" {
+			doc.properties = DecodeDocumentData(data)
+		}
+		if doc.properties == nil {
+			doc.properties = NewMap()
+		}
+	}
+	return doc.properties
+}
+
+// Override
+func (doc *testDocument) GetProperty(name string) any {
+	return doc.Properties()[name]
+}
+
+// Override
+func (doc *testDocument) SetProperty(name string, value any) {
+	properties := doc.Properties()
+	if value == nil {
+		delete(properties, name)
+	} else {
+		properties[name] = value
+	}
+	doc.Remove("data")
+	doc.Remove("signature")
+}
+
+// Override
+func (doc *testDocument) Time() Time {
+	return ParseTime(doc.GetProperty("time"))
+}
+
+type testDocumentHelper struct{}
+
+func (testDocumentHelper) SetDocumentFactory(docType DocumentType, factory DocumentFactory) {}
+
+func (testDocumentHelper) GetDocumentFactory(docType DocumentType) DocumentFactory {
+	return nil
+}
+
+func (testDocumentHelper) CreateDocument(docType DocumentType, data string, signature TransportableData) Document {
+	return newTestDocument(docType)
+}
+
+func (testDocumentHelper) ParseDocument(doc any) Document {
+	return nil
+}
+
+// signTestDocument creates a document for the ID, signed by the key
+func signTestDocument(did ID, docType DocumentType, key *testKey, when float64, properties StringKeyMap) Document {
+	doc := newTestDocument(docType)
+	doc.Set("did", did.String())
+	for name, value := range properties {
+		doc.SetProperty(name, value)
+	}
+	doc.SetProperty("time", when)
+	doc.Sign(key)
+	return doc
+}
+
+var _ SignKey = (*testKey)(nil)
+var _ VerifyKey = (*testKey)(nil)
+var _ Meta = (*testMeta)(nil)
+var _ Document = (*testDocument)(nil)
+
+// testEncryptKey is a visa key, which can encrypt but never verifies
+type testEncryptKey struct {
+	*Dictionary
+
+	name string
+}
+
+func newTestEncryptKey(name string) *testEncryptKey {
+	return &testEncryptKey{
+		Dictionary: NewDictionary(StringKeyMap{"algorithm": "RSA", "name": name}),
+		name:       name,
+	}
+}
+
+// Override
+func (key *testEncryptKey) Algorithm() string {
+	return "RSA"
+}
+
+// Override
+func (key *testEncryptKey) Data() TransportableData {
+	return NewEncodedData([]byte(key.name), nil)
+}
+
+// Override
+func (key *testEncryptKey) Encrypt(plaintext []byte, extra StringKeyMap) []byte {
+	return append([]byte(key.name+":"), plaintext...)
+}
+
+// Override
+func (key *testEncryptKey) Verify(data []byte, signature []byte) bool {
+	return false
+}
+
+// Override
+func (key *testEncryptKey) MatchSignKey(sKey SignKey) bool {
+	return false
+}
+
+// testPublicKeyHelper parses "TEST" (verify only) and "RSA" (encrypt) keys
+type testPublicKeyHelper struct{}
+
+func (testPublicKeyHelper) SetPublicKeyFactory(algorithm string, factory PublicKeyFactory) {}
+
+func (testPublicKeyHelper) GetPublicKeyFactory(algorithm string) PublicKeyFactory {
+	return nil
+}
+
+func (testPublicKeyHelper) ParsePublicKey(key any) PublicKey {
+	info := FetchMap(key)
+	if info == nil {
+		return nil
+	}
+	name := FetchString(info["name"])
+	switch info["algorithm"] {
+	case "TEST":
+		return newTestKey(name)
+	case "RSA":
+		return newTestEncryptKey(name)
+	}
+	return nil
+}
+
+// testFile only carries the PNF map
+type testFile struct {
+	TransportableFile
+
+	info StringKeyMap
+}
+
+// Override
+func (file *testFile) Map() StringKeyMap {
+	return file.info
+}
+
+// Override
+func (file *testFile) URL() URL {
+	return ParseURL(FetchString(file.info["URL"]))
+}
+
+// Override
+func (file *testFile) Serialize() any {
+	return file.info
+}
+
+// testFileHelper parses PNF maps with "URL", and URL strings
+type testFileHelper struct{}
+
+func (testFileHelper) SetTransportableFileFactory(factory TransportableFileFactory) {}
+
+func (testFileHelper) GetTransportableFileFactory() TransportableFileFactory {
+	return nil
+}
+
+func (testFileHelper) ParseTransportableFile(pnf any) TransportableFile {
+	if info := FetchMap(pnf); info != nil {
+		if info["URL"] == nil {
+			return nil
+		}
+		return &testFile{info: info}
+	} else if str := FetchString(pnf); strings.Contains(str, "://") {
+		return &testFile{info: StringKeyMap{"URL": str}}
+	}
+	return nil
+}
+
+func (testFileHelper) CreateTransportableFile(data TransportableData, filename string,
+	url URL, password DecryptKey) TransportableFile {
+	return &testFile{info: StringKeyMap{"URL": url.String(), "filename": filename}}
+}
+
+var _ EncryptKey = (*testEncryptKey)(nil)
+var _ PublicKey = (*testEncryptKey)(nil)
+var _ PublicKey = (*testKey)(nil)
@@ -0,0 +1,193 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	. "github.com/dimchat/mkm-go/format"
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// ChangeKind defines the type of property change
+type ChangeKind uint8
+
+const (
+	PropertyAdded ChangeKind = iota + 1
+	PropertyRemoved
+	PropertyModified
+)
+
+func (kind ChangeKind) String() string {
+	switch kind {
+	case PropertyAdded:
+		return "added"
+	case PropertyRemoved:
+		return "removed"
+	case PropertyModified:
+		return "modified"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", kind)
+}
+
+// PropertyChange describes the change of a document property
+type PropertyChange struct {
+	Name string
+	Kind ChangeKind
+	Old  any // nil when added
+	New  any // nil when removed
+}
+
+// MergeConflict describes a property modified differently on both sides
+type MergeConflict struct {
+	Name   string
+	Base   any
+	Ours   any
+	Theirs any
+}
+
+// DiffProperties compares two property maps
+//
+// Values are compared by canonical JSON, so 1 and 1.0 are equal.
+//
+// Returns: changes sorted by property name
+func DiffProperties(old, new StringKeyMap) []PropertyChange {
+	var changes []PropertyChange
+	for _, name := range unionKeys(old, new) {
+		before, hasOld := old[name]
+		after, hasNew := new[name]
+		if !hasOld || before == nil {
+			if hasNew && after != nil {
+				changes = append(changes, PropertyChange{name, PropertyAdded, nil, after})
+			}
+		} else if !hasNew || after == nil {
+			changes = append(changes, PropertyChange{name, PropertyRemoved, before, nil})
+		} else if !samePropertyValue(before, after) {
+			changes = append(changes, PropertyChange{name, PropertyModified, before, after})
+		}
+	}
+	return changes
+}
+
+// DiffDocuments compares properties of two documents
+func DiffDocuments(old, new Document) []PropertyChange {
+	return DiffProperties(old.Properties(), new.Properties())
+}
+
+// MergeDocuments merges properties changed by two devices since the common ancestor
+//
+//	For each property:
+//	    1. same on both sides       => keep it;
+//	    2. changed on one side only => take the change;
+//	    3. changed on both sides    => conflict, keep ours.
+//
+// The "time" property is not merged, it's set to now;
+// and "prev" is set to the digest of ours, so the merged document continues our chain.
+//
+// Returns: an unsigned document (call Sign() after resolving conflicts), and conflicts
+func MergeDocuments(base, ours, theirs Document) (Document, []MergeConflict, error) {
+	if base == nil || ours == nil || theirs == nil {
+		return nil, nil, errors.New("documents not provided")
+	}
+	owner := documentID(ours)
+	if owner == nil {
+		return nil, nil, errors.New("document ID not found")
+	}
+	for _, doc := range []Document{base, theirs} {
+		if did := documentID(doc); did == nil || did.String() != owner.String() {
+			return nil, nil, fmt.Errorf("document ID not match: %s", owner.String())
+		}
+	}
+	baseProps := base.Properties()
+	ourProps := ours.Properties()
+	theirProps := theirs.Properties()
+	merged := NewMap()
+	var conflicts []MergeConflict
+	for _, name := range unionKeys(baseProps, ourProps, theirProps) {
+		if name == "time" || name == "prev" {
+			continue
+		}
+		b, o, t := baseProps[name], ourProps[name], theirProps[name]
+		var value any
+		if samePropertyValue(o, t) || samePropertyValue(b, t) {
+			value = o
+		} else if samePropertyValue(b, o) {
+			value = t
+		} else {
+			conflicts = append(conflicts, MergeConflict{name, b, o, t})
+			value = o
+		}
+		if value != nil {
+			merged[name] = value
+		}
+	}
+	docType := documentType(ours)
+	doc := CreateDocument(docType, "", nil)
+	if doc == nil {
+		return nil, conflicts, fmt.Errorf("failed to create document: %s", docType)
+	}
+	doc.Set("did", owner.String())
+	for name, value := range merged {
+		doc.SetProperty(name, value)
+	}
+	if prev := DocumentDigest(ours); prev != "" {
+		doc.SetProperty("prev", prev)
+	}
+	doc.SetProperty("time", TimeToFloat64(TimeNow()))
+	return doc, conflicts, nil
+}
+
+func unionKeys(maps ...StringKeyMap) []string {
+	exists := map[string]bool{}
+	var keys []string
+	for _, dict := range maps {
+		for name := range dict {
+			if !exists[name] {
+				exists[name] = true
+				keys = append(keys, name)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// samePropertyValue compares values by canonical JSON (nil equals absent)
+func samePropertyValue(a, b any) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	coder := CanonicalJSONCoder{}
+	x, y := coder.Encode(a), coder.Encode(b)
+	if x == "" || y == "" {
+		// failed to encode
+		return false
+	}
+	return x == y
+}
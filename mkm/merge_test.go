@@ -0,0 +1,136 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"testing"
+
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func TestDiffProperties(t *testing.T) {
+	old := StringKeyMap{
+		"name":   "Moky",
+		"avatar": "https://example.com/a.png",
+		"age":    18,
+		"keep":   []any{1, 2},
+	}
+	new := StringKeyMap{
+		"name":  "Albert",
+		"age":   18.0,
+		"email": "moky@example.com",
+		"keep":  []any{1.0, 2.0},
+	}
+	changes := DiffProperties(old, new)
+	expected := []PropertyChange{
+		{"avatar", PropertyRemoved, "https://example.com/a.png", nil},
+		{"email", PropertyAdded, nil, "moky@example.com"},
+		{"name", PropertyModified, "Moky", "Albert"},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("changes: %v", changes)
+	}
+	for i, change := range changes {
+		if change != expected[i] {
+			t.Errorf("change #%d: %v, expected %v", i, change, expected[i])
+		}
+	}
+	if len(DiffProperties(old, old)) != 0 {
+		t.Errorf("changes between same properties")
+	}
+	if PropertyModified.String() != "modified" || ChangeKind(9).String() != "ChangeKind(9)" {
+		t.Errorf("change kind: %s", ChangeKind(9))
+	}
+}
+
+func TestMergeDocuments(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	base := signTestDocument(did, VISA, key, 1000, StringKeyMap{
+		"name":   "Moky",
+		"avatar": "a.png",
+		"email":  "old@example.com",
+	})
+	ours := signTestDocument(did, VISA, key, 2000, StringKeyMap{
+		"name":   "Albert",
+		"avatar": "a.png",
+		"email":  "old@example.com",
+	})
+	theirs := signTestDocument(did, VISA, key, 3000, StringKeyMap{
+		"name":   "Moky",
+		"avatar": "b.png",
+		"phone":  "10086",
+	})
+	merged, conflicts, err := MergeDocuments(base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 0 {
+		t.Errorf("conflicts: %v", conflicts)
+	}
+	props := merged.Properties()
+	if props["name"] != "Albert" || props["avatar"] != "b.png" || props["phone"] != "10086" {
+		t.Errorf("merged: %v", props)
+	}
+	if _, exists := props["email"]; exists {
+		t.Errorf("removed property kept: %v", props)
+	}
+	if documentID(merged).String() != did.String() || documentType(merged) != VISA {
+		t.Errorf("merged document ID or type not match")
+	}
+	if TimeToFloat64(merged.Time()) <= 3000 {
+		t.Errorf("merged time: %v", merged.Time())
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	base := signTestDocument(did, VISA, key, 1000, StringKeyMap{"name": "Moky"})
+	ours := signTestDocument(did, VISA, key, 2000, StringKeyMap{"name": "Albert"})
+	theirs := signTestDocument(did, VISA, key, 3000, StringKeyMap{"name": "Hulk"})
+	merged, conflicts, err := MergeDocuments(base, ours, theirs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 || conflicts[0] != (MergeConflict{"name", "Moky", "Albert", "Hulk"}) {
+		t.Errorf("conflicts: %v", conflicts)
+	}
+	if merged.GetProperty("name") != "Albert" {
+		t.Errorf("conflict not resolved with ours: %v", merged.GetProperty("name"))
+	}
+	other := newTestMeta(key, "hulk").ID("")
+	foreign := signTestDocument(other, VISA, key, 3000, StringKeyMap{"name": "Hulk"})
+	if _, _, err = MergeDocuments(base, ours, foreign); err == nil {
+		t.Errorf("merged documents of another entity")
+	}
+	if _, _, err = MergeDocuments(nil, ours, theirs); err == nil {
+		t.Errorf("merged without base")
+	}
+}
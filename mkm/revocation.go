@@ -0,0 +1,243 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	. "github.com/dimchat/mkm-go/crypto"
+	. "github.com/dimchat/mkm-go/digest"
+	. "github.com/dimchat/mkm-go/format"
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+/**
+ *  Document Revocation
+ *
+ *      {
+ *          "data"      : "{JSON}",          // canonical JSON of info
+ *          "signature" : "{BASE64_ENCODE}"  // signature = sign(data, SK);
+ *      }
+ *
+ *      info: {
+ *          "did"     : "{EntityID}",        // owner of the revoked document
+ *          "revoked" : "sha256:{HEX}",      // digest of the revoked document's signature
+ *          "reason"  : "key compromised",
+ *          "time"    : 123
+ *      }
+ */
+
+// Revocation is a signed statement declaring a document invalid
+type Revocation struct {
+	//Mapper
+	*Dictionary
+
+	properties StringKeyMap
+}
+
+// NewRevocation creates a revocation for the document, signed with sKey (matches meta.key)
+func NewRevocation(doc Document, reason string, sKey SignKey) (*Revocation, error) {
+	if doc == nil {
+		return nil, errors.New("document not provided")
+	} else if sKey == nil {
+		return nil, errors.New("sign key not provided")
+	}
+	owner := documentID(doc)
+	if owner == nil {
+		return nil, errors.New("document ID not found")
+	}
+	revoked := DocumentSignatureDigest(doc)
+	if revoked == "" {
+		return nil, fmt.Errorf("document signature not found: %s", owner.String())
+	}
+	properties := StringKeyMap{
+		"did":     owner.String(),
+		"revoked": revoked,
+		"time":    TimeToFloat64(TimeNow()),
+	}
+	if reason != "" {
+		properties["reason"] = reason
+	}
+	data := CanonicalJSONCoder{}.Encode(properties)
+	signature := sKey.Sign(UTF8Encode(data))
+	if len(signature) == 0 {
+		return nil, fmt.Errorf("failed to sign revocation: %s", owner.String())
+	}
+	return &Revocation{
+		Dictionary: NewDictionary(StringKeyMap{
+			"data":      data,
+			"signature": NewEncodedData(signature, nil).Serialize(),
+		}),
+		properties: properties,
+	}, nil
+}
+
+// ParseRevocation parses the revocation map (nil if malformed)
+func ParseRevocation(info any) *Revocation {
+	dict := FetchMap(info)
+	if dict == nil {
+		return nil
+	}
+	revocation := &Revocation{
+		Dictionary: NewDictionary(dict),
+	}
+	data := revocation.GetString("data", "")
+	if data == "" || revocation.GetString("signature", "") == "" {
+		return nil
+	}
+	revocation.properties = FetchMap(CanonicalJSONCoder{}.Decode(data))
+	if revocation.properties == nil || revocation.Revoked() == "" || revocation.ID() == nil {
+		return nil
+	}
+	return revocation
+}
+
+// ID returns the owner of the revoked document
+func (revocation *Revocation) ID() ID {
+	return ParseID(revocation.properties["did"])
+}
+
+// Revoked returns the digest of the revoked document's signature
+func (revocation *Revocation) Revoked() string {
+	return FetchString(revocation.properties["revoked"])
+}
+
+func (revocation *Revocation) Reason() string {
+	value := revocation.properties["reason"]
+	if value == nil {
+		return ""
+	}
+	return FetchString(value)
+}
+
+func (revocation *Revocation) Time() Time {
+	return ParseTime(revocation.properties["time"])
+}
+
+// Verify checks the signature with meta.key
+func (revocation *Revocation) Verify(metaKey VerifyKey) bool {
+	if metaKey == nil {
+		return false
+	}
+	ted := ParseTransportableData(revocation.Get("signature"))
+	if ted == nil {
+		return false
+	}
+	signature := ted.Bytes()
+	if len(signature) == 0 {
+		return false
+	}
+	data := revocation.GetString("data", "")
+	return metaKey.Verify(UTF8Encode(data), signature)
+}
+
+// DocumentSignatureDigest returns the digest of the document's signature (empty if not signed)
+func DocumentSignatureDigest(doc Document) string {
+	ted := ParseTransportableData(doc.Get("signature"))
+	if ted == nil {
+		return ""
+	}
+	signature := ted.Bytes()
+	if len(signature) == 0 {
+		return ""
+	}
+	return ContentDigest("sha256", signature)
+}
+
+//
+//  Revocation Set
+//
+
+// RevocationSet keeps verified revocations, and is consulted by DocumentSelector
+type RevocationSet struct {
+	mutex   sync.RWMutex
+	records map[string]*Revocation // owner + revoked digest => revocation
+}
+
+func NewRevocationSet() *RevocationSet {
+	return &RevocationSet{
+		records: map[string]*Revocation{},
+	}
+}
+
+// Add verifies the revocation with meta.key and keeps it
+//
+// The meta must match the owner ID of the revocation (see MetaMatchID()),
+// and the first record for a document is kept, later ones will not overwrite it.
+func (set *RevocationSet) Add(meta Meta, revocation *Revocation) error {
+	if meta == nil || revocation == nil {
+		return errors.New("meta or revocation not provided")
+	}
+	owner := revocation.ID()
+	if owner == nil {
+		return errors.New("revocation ID not found")
+	} else if !MetaMatchID(meta, owner) {
+		return fmt.Errorf("meta not match revocation ID: %s", owner.String())
+	} else if !revocation.Verify(meta.PublicKey()) {
+		return fmt.Errorf("revocation signature not match: %s", owner.String())
+	}
+	key := revocationKey(owner, revocation.Revoked())
+	set.mutex.Lock()
+	defer set.mutex.Unlock()
+	if old := set.records[key]; old != nil {
+		if old.GetString("data", "") == revocation.GetString("data", "") {
+			// same revocation
+			return nil
+		}
+		return fmt.Errorf("document already revoked: %s, %s", owner.String(), revocation.Revoked())
+	}
+	set.records[key] = revocation
+	return nil
+}
+
+// Get returns the revocation of the document (nil if not revoked)
+func (set *RevocationSet) Get(doc Document) *Revocation {
+	digest := DocumentSignatureDigest(doc)
+	if digest == "" {
+		return nil
+	}
+	owner := documentID(doc)
+	if owner == nil {
+		return nil
+	}
+	set.mutex.RLock()
+	defer set.mutex.RUnlock()
+	return set.records[revocationKey(owner, digest)]
+}
+
+// revocationKey builds the record key with owner (ignoring terminal) and revoked digest,
+// so a revocation signed by another entity never matches the document
+func revocationKey(owner ID, digest string) string {
+	return owner.Name() + "@" + owner.Address().String() + "#" + digest
+}
+
+// IsRevoked checks whether the document is revoked
+func (set *RevocationSet) IsRevoked(doc Document) bool {
+	return set.Get(doc) != nil
+}
@@ -0,0 +1,177 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/dimchat/mkm-go/format"
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func TestRevocationRoundTrip(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	doc := signTestDocument(did, VISA, key, 1000, nil)
+	revocation, err := NewRevocation(doc, "key leaked", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed := ParseRevocation(revocation.Map())
+	if parsed == nil {
+		t.Fatalf("failed to parse revocation: %v", revocation.Map())
+	}
+	if parsed.ID().String() != did.String() || parsed.Reason() != "key leaked" {
+		t.Errorf("revocation: %v", parsed.Map())
+	}
+	if parsed.Revoked() != DocumentSignatureDigest(doc) {
+		t.Errorf("revoked digest: %s", parsed.Revoked())
+	}
+	if !parsed.Verify(meta.PublicKey()) {
+		t.Errorf("revocation not verified")
+	}
+	if parsed.Verify(newTestKey("evil-key")) {
+		t.Errorf("revocation verified with another key")
+	}
+	// signature is serialized as TED, the same as documents
+	ted := ParseTransportableData(revocation.Get("signature"))
+	data := UTF8Encode(revocation.GetString("data", ""))
+	if ted == nil || string(ted.Bytes()) != string(key.Sign(data)) {
+		t.Errorf("revocation signature: %v", revocation.Get("signature"))
+	}
+	if ParseRevocation(StringKeyMap{"data": "{}", "signature": "AA=="}) != nil {
+		t.Errorf("malformed revocation parsed")
+	}
+	unsigned := newTestDocument(VISA)
+	unsigned.Set("did", did.String())
+	if _, err = NewRevocation(unsigned, "", key); err == nil {
+		t.Errorf("revoked unsigned document")
+	}
+}
+
+func TestRevocationSet(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	doc := signTestDocument(did, VISA, key, 1000, StringKeyMap{"name": "old"})
+	current := signTestDocument(did, VISA, key, 500, StringKeyMap{"name": "current"})
+	revocation, _ := NewRevocation(doc, "superseded", key)
+	set := NewRevocationSet()
+	if set.IsRevoked(doc) {
+		t.Fatalf("revoked before add")
+	}
+	if err := set.Add(meta, revocation); err != nil {
+		t.Fatal(err)
+	}
+	if !set.IsRevoked(doc) || set.IsRevoked(current) {
+		t.Errorf("revocation not match")
+	}
+	// the same statement again is accepted
+	if err := set.Add(meta, ParseRevocation(revocation.Map())); err != nil {
+		t.Errorf("same revocation rejected: %v", err)
+	}
+	// a later statement must not overwrite the first record
+	later, _ := NewRevocation(doc, "another reason", key)
+	if err := set.Add(meta, later); err == nil {
+		t.Errorf("revocation overwritten")
+	}
+	if got := set.Get(doc); got == nil || got.Reason() != "superseded" {
+		t.Errorf("revocation: %v", got)
+	}
+	// selector skips the revoked document
+	selector := NewDocumentSelector()
+	selector.Revocations = set
+	selected := selector.Select(meta, did, VISA, []Document{doc, current})
+	if selected == nil || selected.GetProperty("name") != "current" {
+		t.Errorf("selected: %v", selected)
+	}
+}
+
+func TestRevocationForged(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	doc := signTestDocument(did, VISA, key, 1000, nil)
+	evilKey := newTestKey("evil-key")
+	evilMeta := newTestMeta(evilKey, "evil")
+	set := NewRevocationSet()
+	// revocation of another entity's document, signed with the attacker's key
+	forged, _ := NewRevocation(doc, "forged", evilKey)
+	if err := set.Add(evilMeta, forged); err == nil {
+		t.Errorf("revocation added with foreign meta")
+	}
+	if err := set.Add(meta, forged); err == nil {
+		t.Errorf("revocation added with forged signature")
+	}
+	// attacker copies the signature into a document of their own
+	copied := newTestDocument(VISA)
+	copied.Set("did", evilMeta.ID("").String())
+	copied.Set("data", doc.Get("data"))
+	copied.Set("signature", doc.Get("signature"))
+	revocation, _ := NewRevocation(copied, "copied", evilKey)
+	if err := set.Add(evilMeta, revocation); err != nil {
+		t.Fatal(err)
+	}
+	if set.IsRevoked(doc) {
+		t.Errorf("document revoked by another entity")
+	}
+}
+
+func TestRevocationSetConcurrency(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	documents := make([]Document, 16)
+	revocations := make([]*Revocation, len(documents))
+	for i := range documents {
+		documents[i] = signTestDocument(did, VISA, key, float64(1000+i), nil)
+		revocations[i], _ = NewRevocation(documents[i], "", key)
+	}
+	set := NewRevocationSet()
+	var wg sync.WaitGroup
+	for i := range documents {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			if err := set.Add(meta, revocations[i]); err != nil {
+				t.Error(err)
+			}
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			set.IsRevoked(documents[i])
+		}(i)
+	}
+	wg.Wait()
+	for i, doc := range documents {
+		if !set.IsRevoked(doc) {
+			t.Errorf("document %d not revoked", i)
+		}
+	}
+}
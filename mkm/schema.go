@@ -0,0 +1,242 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	. "github.com/dimchat/mkm-go/crypto"
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// PropertyKind defines the expected type of a document property
+type PropertyKind uint8
+
+const (
+	AnyProperty       PropertyKind = iota
+	StringProperty                 // string
+	NumberProperty                 // int, float, json.Number, ...
+	BoolProperty                   // true/false
+	TimeProperty                   // timestamp in seconds
+	IDProperty                     // "name@address[/terminal]"
+	IDListProperty                 // ["name@address", ...]
+	PNFProperty                    // URL string or PNF map
+	PublicKeyProperty              // public key for encryption
+	MapProperty                    // {...}
+	ListProperty                   // [...]
+)
+
+// PropertyRule describes a document property
+type PropertyRule struct {
+	Name     string
+	Kind     PropertyKind
+	Required bool
+
+	// MaxLength limits characters of string, or items of list (0 means no limit)
+	MaxLength int
+}
+
+// SchemaViolation describes a property that does not follow the schema
+type SchemaViolation struct {
+	Property string
+	Reason   string
+}
+
+func (violation SchemaViolation) String() string {
+	return fmt.Sprintf("%s: %s", violation.Property, violation.Reason)
+}
+
+func (violation SchemaViolation) Error() string {
+	return violation.String()
+}
+
+// DocumentSchema describes properties of a document type
+//
+// Properties not listed in rules are not checked.
+type DocumentSchema struct {
+	Rules []PropertyRule
+}
+
+func NewDocumentSchema(rules ...PropertyRule) *DocumentSchema {
+	return &DocumentSchema{
+		Rules: rules,
+	}
+}
+
+// Validate checks document properties with rules
+//
+// Returns: all violations (empty if the document follows the schema)
+func (schema *DocumentSchema) Validate(doc Document) []SchemaViolation {
+	var violations []SchemaViolation
+	for _, rule := range schema.Rules {
+		value := doc.GetProperty(rule.Name)
+		if ValueIsNil(value) {
+			if rule.Required {
+				violations = append(violations, SchemaViolation{rule.Name, "required property not found"})
+			}
+			continue
+		}
+		if reason := checkProperty(rule, value); reason != "" {
+			violations = append(violations, SchemaViolation{rule.Name, reason})
+		}
+	}
+	return violations
+}
+
+//
+//  Schema Registry
+//
+
+var documentSchemas = map[DocumentType]*DocumentSchema{
+	VISA: NewDocumentSchema(
+		PropertyRule{Name: "name", Kind: StringProperty, MaxLength: 64},
+		PropertyRule{Name: "avatar", Kind: PNFProperty},
+		PropertyRule{Name: "key", Kind: PublicKeyProperty},
+		PropertyRule{Name: "time", Kind: TimeProperty, Required: true},
+		PropertyRule{Name: "not_before", Kind: TimeProperty},
+		PropertyRule{Name: "expires", Kind: TimeProperty},
+		PropertyRule{Name: "prev", Kind: StringProperty},
+	),
+	BULLETIN: NewDocumentSchema(
+		PropertyRule{Name: "name", Kind: StringProperty, MaxLength: 64},
+		PropertyRule{Name: "founder", Kind: IDProperty, Required: true},
+		PropertyRule{Name: "owner", Kind: IDProperty},
+		PropertyRule{Name: "administrators", Kind: IDListProperty, MaxLength: 256},
+		PropertyRule{Name: "assistants", Kind: IDListProperty, MaxLength: 16},
+		PropertyRule{Name: "time", Kind: TimeProperty, Required: true},
+		PropertyRule{Name: "not_before", Kind: TimeProperty},
+		PropertyRule{Name: "expires", Kind: TimeProperty},
+		PropertyRule{Name: "prev", Kind: StringProperty},
+	),
+}
+
+// SetDocumentSchema registers the schema for document type (nil to remove)
+func SetDocumentSchema(docType DocumentType, schema *DocumentSchema) {
+	if schema == nil {
+		delete(documentSchemas, docType)
+	} else {
+		documentSchemas[docType] = schema
+	}
+}
+
+func GetDocumentSchema(docType DocumentType) *DocumentSchema {
+	return documentSchemas[docType]
+}
+
+// ValidateDocument checks document properties with the schema of its type
+//
+// Returns: all violations (empty if no schema registered for the type)
+func ValidateDocument(doc Document) []SchemaViolation {
+	schema := GetDocumentSchema(documentType(doc))
+	if schema == nil {
+		return nil
+	}
+	return schema.Validate(doc)
+}
+
+// checkProperty returns the reason if value not match the rule (empty if matched)
+func checkProperty(rule PropertyRule, value any) string {
+	switch rule.Kind {
+	case StringProperty:
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Sprintf("expected string, got %T", value)
+		} else if rule.MaxLength > 0 && utf8.RuneCountInString(str) > rule.MaxLength {
+			return fmt.Sprintf("too long: %d > %d", utf8.RuneCountInString(str), rule.MaxLength)
+		}
+	case NumberProperty, TimeProperty:
+		if !isNumber(value) {
+			return fmt.Sprintf("expected number, got %T", value)
+		} else if rule.Kind == TimeProperty && ConvertFloat64(value, 0) <= 0 {
+			// same as ParseTime()
+			return "timestamp not positive"
+		}
+	case BoolProperty:
+		if _, ok := value.(bool); !ok {
+			return fmt.Sprintf("expected bool, got %T", value)
+		}
+	case IDProperty:
+		if _, ok := value.(string); !ok {
+			return fmt.Sprintf("expected ID string, got %T", value)
+		} else if ParseID(value) == nil {
+			return "malformed ID"
+		}
+	case IDListProperty:
+		array := FetchList(value)
+		if array == nil {
+			return fmt.Sprintf("expected ID list, got %T", value)
+		} else if rule.MaxLength > 0 && len(array) > rule.MaxLength {
+			return fmt.Sprintf("too many items: %d > %d", len(array), rule.MaxLength)
+		}
+		for index, item := range array {
+			if _, ok := item.(string); !ok || ParseID(item) == nil {
+				return fmt.Sprintf("malformed ID at index %d", index)
+			}
+		}
+	case PNFProperty:
+		switch value.(type) {
+		case string, StringKeyMap:
+		default:
+			if FetchMap(value) == nil {
+				return fmt.Sprintf("expected URL or PNF map, got %T", value)
+			}
+		}
+		if ParseTransportableFile(value) == nil {
+			return "malformed PNF"
+		}
+	case PublicKeyProperty:
+		if FetchMap(value) == nil {
+			return fmt.Sprintf("expected key map, got %T", value)
+		} else if _, ok := ParsePublicKey(value).(EncryptKey); !ok {
+			return "not a public key for encryption"
+		}
+	case MapProperty:
+		if FetchMap(value) == nil {
+			return fmt.Sprintf("expected map, got %T", value)
+		}
+	case ListProperty:
+		array := FetchList(value)
+		if array == nil {
+			return fmt.Sprintf("expected list, got %T", value)
+		} else if rule.MaxLength > 0 && len(array) > rule.MaxLength {
+			return fmt.Sprintf("too many items: %d > %d", len(array), rule.MaxLength)
+		}
+	}
+	return ""
+}
+
+func isNumber(value any) bool {
+	switch value.(type) {
+	case int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64,
+		float32, float64, json.Number:
+		return true
+	}
+	return false
+}
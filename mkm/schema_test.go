@@ -0,0 +1,179 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// testViolations validates the properties, and returns the names of violated properties
+func testViolations(docType DocumentType, properties StringKeyMap) []string {
+	doc := newTestDocument(docType)
+	for name, value := range properties {
+		doc.SetProperty(name, value)
+	}
+	var names []string
+	for _, violation := range ValidateDocument(doc) {
+		names = append(names, violation.Property)
+	}
+	return names
+}
+
+func TestVisaSchema(t *testing.T) {
+	valid := func() StringKeyMap {
+		return StringKeyMap{
+			"name":   "Moky",
+			"avatar": "https://cdn.example.com/avatar.png",
+			"key":    newTestEncryptKey("visa-key").Map(),
+			"time":   1700000000.5,
+		}
+	}
+	if names := testViolations(VISA, valid()); len(names) != 0 {
+		t.Fatalf("valid visa: %v", names)
+	}
+	cases := []struct {
+		name     string
+		property string
+		value    any
+	}{
+		{"numeric name", "name", 12345},
+		{"name too long", "name", strings.Repeat("中", 65)},
+		{"avatar number", "avatar", 42},
+		{"avatar list", "avatar", []any{"https://cdn.example.com/a.png"}},
+		{"avatar not URL", "avatar", "not a url"},
+		{"avatar map without URL", "avatar", StringKeyMap{"filename": "a.png"}},
+		{"key string", "key", "MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEA"},
+		{"verify-only key", "key", newTestKey("meta-key").Map()},
+		{"time string", "time", "yesterday"},
+		{"time zero", "time", 0},
+		{"time negative", "time", -1},
+		{"time missing", "time", nil},
+		{"expires bool", "expires", true},
+		{"prev number", "prev", 1},
+	}
+	for _, item := range cases {
+		props := valid()
+		props[item.property] = item.value
+		names := testViolations(VISA, props)
+		if len(names) != 1 || names[0] != item.property {
+			t.Errorf("%s: violations %v", item.name, names)
+		}
+	}
+	// boundary
+	props := valid()
+	props["name"] = strings.Repeat("中", 64)
+	props["avatar"] = StringKeyMap{"URL": "https://cdn.example.com/a.png"}
+	if names := testViolations(VISA, props); len(names) != 0 {
+		t.Errorf("boundary: %v", names)
+	}
+	// all violations reported
+	if names := testViolations(VISA, StringKeyMap{"name": 1, "avatar": 2}); len(names) != 3 {
+		t.Errorf("violations: %v", names)
+	}
+}
+
+func TestBulletinSchema(t *testing.T) {
+	founder := testUserID("founder").String()
+	alice := testUserID("alice").String()
+	valid := func() StringKeyMap {
+		return StringKeyMap{
+			"name":           "Group",
+			"founder":        founder,
+			"owner":          alice,
+			"administrators": []string{alice, founder},
+			"assistants":     []any{alice},
+			"time":           1700000000,
+		}
+	}
+	if names := testViolations(BULLETIN, valid()); len(names) != 0 {
+		t.Fatalf("valid bulletin: %v", names)
+	}
+	bots := make([]any, 17)
+	for i := range bots {
+		bots[i] = alice
+	}
+	cases := []struct {
+		name     string
+		property string
+		value    any
+	}{
+		{"founder missing", "founder", nil},
+		{"founder malformed", "founder", "garbage"},
+		{"founder number", "founder", 42},
+		{"owner malformed", "owner", "garbage"},
+		{"administrators not list", "administrators", alice},
+		{"administrator malformed", "administrators", []any{alice, "garbage"}},
+		{"administrator number", "administrators", []any{42}},
+		{"too many assistants", "assistants", bots},
+		{"name map", "name", StringKeyMap{}},
+	}
+	for _, item := range cases {
+		props := valid()
+		props[item.property] = item.value
+		names := testViolations(BULLETIN, props)
+		if len(names) != 1 || names[0] != item.property {
+			t.Errorf("%s: violations %v", item.name, names)
+		}
+	}
+}
+
+func TestDocumentSchemaRegistry(t *testing.T) {
+	if names := testViolations("custom", StringKeyMap{"age": "old"}); len(names) != 0 {
+		t.Errorf("document without schema: %v", names)
+	}
+	schema := NewDocumentSchema(
+		PropertyRule{Name: "age", Kind: NumberProperty, Required: true},
+		PropertyRule{Name: "vip", Kind: BoolProperty},
+		PropertyRule{Name: "tags", Kind: ListProperty, MaxLength: 2},
+		PropertyRule{Name: "extra", Kind: MapProperty},
+		PropertyRule{Name: "anything", Kind: AnyProperty},
+	)
+	SetDocumentSchema("custom", schema)
+	defer SetDocumentSchema("custom", nil)
+	if GetDocumentSchema("custom") != schema {
+		t.Fatalf("schema not registered")
+	}
+	props := StringKeyMap{
+		"age":      "old",
+		"vip":      "yes",
+		"tags":     []any{1, 2, 3},
+		"extra":    "{}",
+		"anything": []any{nil},
+	}
+	names := testViolations("custom", props)
+	if strings.Join(names, ",") != "age,vip,tags,extra" {
+		t.Errorf("violations: %v", names)
+	}
+	doc := newTestDocument("custom")
+	violations := ValidateDocument(doc)
+	if len(violations) != 1 || violations[0].Error() != "age: required property not found" {
+		t.Errorf("violations: %v", violations)
+	}
+}
@@ -0,0 +1,200 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	. "github.com/dimchat/mkm-go/ext"
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// DocumentSelector picks the newest valid document from candidates
+type DocumentSelector struct {
+
+	// MaxSkew is the tolerance for documents dated in the future
+	MaxSkew time.Duration
+
+	// Now returns the current time (default: TimeNow)
+	Now func() Time
+
+	// CheckValidity rejects documents out of the "not_before"/"expires" window
+	CheckValidity bool
+
+	// Revocations rejects revoked documents (optional)
+	Revocations *RevocationSet
+}
+
+func NewDocumentSelector() *DocumentSelector {
+	return &DocumentSelector{
+		MaxSkew: 5 * time.Minute,
+	}
+}
+
+var defaultDocumentSelector = NewDocumentSelector()
+
+// SelectDocument picks the newest valid document with the default selector
+func SelectDocument(meta Meta, did ID, docType DocumentType, documents []Document) Document {
+	return defaultDocumentSelector.Select(meta, did, docType, documents)
+}
+
+func (selector *DocumentSelector) now() Time {
+	if selector.Now == nil {
+		return TimeNow()
+	}
+	return selector.Now()
+}
+
+// Select returns the newest document accepted by Check() (nil if none)
+//
+// When ID has a terminal, documents of that terminal are preferred,
+// and the documents of the base ID are used as fallback.
+//
+// Documents with the same time keep their original order.
+func (selector *DocumentSelector) Select(meta Meta, did ID, docType DocumentType, documents []Document) Document {
+	var newest, fallback Document
+	var newestTime, fallbackTime int64
+	for _, doc := range documents {
+		if selector.Check(meta, did, docType, doc) != nil {
+			continue
+		}
+		when := documentTime(doc)
+		if owner := documentID(doc); owner.Terminal() != did.Terminal() {
+			// base document for terminal ID
+			if fallback == nil || when > fallbackTime {
+				fallback = doc
+				fallbackTime = when
+			}
+		} else if newest == nil || when > newestTime {
+			newest = doc
+			newestTime = when
+		}
+	}
+	if newest == nil {
+		return fallback
+	}
+	return newest
+}
+
+// Check validates the document for the entity
+//
+//	Rules:
+//	    0. meta must match the entity ID (see MetaMatchID());
+//	    1. owner ID must match, documents of the base ID are accepted for terminal ID
+//	       (and terminal documents for other terminals are rejected, see SelectTerminalVisas());
+//	    2. document type must match (documents without "type" are accepted);
+//	    3. signature must be verified with meta.key;
+//	    4. document time must not be later than now + MaxSkew;
+//	    5. now must be in the validity window (when CheckValidity is set);
+//	    6. document must not be revoked (when Revocations is set).
+//
+// Returns: nil if the document is acceptable, otherwise error describing the reason
+func (selector *DocumentSelector) Check(meta Meta, did ID, docType DocumentType, doc Document) error {
+	if doc == nil {
+		return errors.New("document empty")
+	} else if did == nil {
+		return errors.New("entity ID not provided")
+	} else if meta == nil {
+		return errors.New("meta not provided")
+	} else if !MetaMatchID(meta, did) {
+		return fmt.Errorf("meta not match ID: %s", did.String())
+	}
+	// check owner
+	owner := documentID(doc)
+	if owner == nil {
+		return errors.New("document ID not found")
+	} else if !sameEntity(owner, did) || !sameTerminal(owner, did) {
+		return fmt.Errorf("document ID not match: %s, %s", owner.String(), did.String())
+	}
+	// check type
+	if docType != "" {
+		if actual := documentType(doc); actual != "" && actual != docType {
+			return fmt.Errorf("document type not match: %s, %s", actual, docType)
+		}
+	}
+	// check signature
+	if !doc.Verify(meta.PublicKey()) {
+		return fmt.Errorf("document signature not match: %s", owner.String())
+	}
+	// check time
+	now := selector.now()
+	if when := documentTime(doc); when > 0 {
+		limit := now.UnixNano() + int64(selector.MaxSkew)
+		if when > limit {
+			return fmt.Errorf("document time in the future: %s, %v", owner.String(), doc.Time())
+		}
+	}
+	// check validity window
+	if selector.CheckValidity {
+		if err := CheckDocumentValidity(doc, now); err != nil {
+			return fmt.Errorf("%w: %s", err, owner.String())
+		}
+	}
+	// check revocations
+	if revocations := selector.Revocations; revocations != nil {
+		if revocation := revocations.Get(doc); revocation != nil {
+			return fmt.Errorf("document revoked: %s, %s", owner.String(), revocation.Reason())
+		}
+	}
+	return nil
+}
+
+// documentTime returns the signing time in nanoseconds (0 if not found)
+func documentTime(doc Document) int64 {
+	when := doc.Time()
+	if TimeIsNil(when) {
+		return 0
+	}
+	return when.UnixNano()
+}
+
+func documentID(doc Document) ID {
+	if helper := GetGeneralAccountHelper(); helper != nil {
+		return helper.GetDocumentID(doc.Map())
+	}
+	return ParseID(doc.Get("did"))
+}
+
+func documentType(doc Document) DocumentType {
+	if helper := GetGeneralAccountHelper(); helper != nil {
+		return helper.GetDocumentType(doc.Map(), "")
+	}
+	return doc.GetString("type", "")
+}
+
+// sameTerminal checks whether the document owner matches the terminal of the ID,
+// documents of the base ID match all terminals
+func sameTerminal(owner, did ID) bool {
+	return owner.Terminal() == "" || owner.Terminal() == did.Terminal()
+}
+
+// sameEntity checks whether two IDs point to the same entity (ignoring terminal)
+func sameEntity(a, b ID) bool {
+	return a.Name() == b.Name() && a.Address().String() == b.Address().String()
+}
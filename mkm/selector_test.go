@@ -0,0 +1,138 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"sync"
+	"testing"
+
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func TestSelectNewestDocument(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	documents := []Document{
+		signTestDocument(did, VISA, key, 1000, StringKeyMap{"name": "v1"}),
+		signTestDocument(did, VISA, key, 3000, StringKeyMap{"name": "v3"}),
+		signTestDocument(did, VISA, key, 2000, StringKeyMap{"name": "v2"}),
+		signTestDocument(did, BULLETIN, key, 4000, StringKeyMap{"name": "bulletin"}),
+	}
+	doc := SelectDocument(meta, did, VISA, documents)
+	if doc == nil || doc.GetProperty("name") != "v3" {
+		t.Fatalf("selected: %v", doc)
+	}
+	if doc = SelectDocument(meta, did, VISA, nil); doc != nil {
+		t.Errorf("selected from empty: %v", doc)
+	}
+}
+
+func TestSelectorRejects(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	evilKey := newTestKey("evil-key")
+	evilMeta := newTestMeta(evilKey, "moky")
+	evilID := evilMeta.ID("")
+	good := signTestDocument(did, VISA, key, 1000, nil)
+	forged := signTestDocument(did, VISA, evilKey, 2000, nil)
+	foreign := signTestDocument(evilID, VISA, evilKey, 2000, nil)
+	selector := NewDocumentSelector()
+	if err := selector.Check(meta, did, VISA, good); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name string
+		meta Meta
+		did  ID
+		doc  Document
+	}{
+		{"nil ID", meta, nil, good},
+		{"nil meta", nil, did, good},
+		{"nil document", meta, did, nil},
+		{"foreign meta", evilMeta, did, forged},
+		{"forged signature", meta, did, forged},
+		{"other owner", meta, did, foreign},
+		{"wrong type", meta, did, signTestDocument(did, BULLETIN, key, 1000, nil)},
+		{"other terminal", meta, meta.ID("pad"), signTestDocument(meta.ID("phone"), VISA, key, 1000, nil)},
+	}
+	for _, item := range cases {
+		if err := selector.Check(item.meta, item.did, VISA, item.doc); err == nil {
+			t.Errorf("%s: expected error", item.name)
+		}
+	}
+	// a foreign meta must not make another entity's documents acceptable
+	if doc := selector.Select(evilMeta, did, VISA, []Document{good, forged}); doc != nil {
+		t.Errorf("selected with foreign meta: %v", doc)
+	}
+	if doc := selector.Select(meta, nil, VISA, []Document{good}); doc != nil {
+		t.Errorf("selected without ID: %v", doc)
+	}
+}
+
+func TestSelectorFutureDocument(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	selector := NewDocumentSelector()
+	selector.Now = func() Time {
+		return TimeFromFloat64(1000)
+	}
+	current := signTestDocument(did, VISA, key, 1000, StringKeyMap{"name": "current"})
+	future := signTestDocument(did, VISA, key, 1000+3600, StringKeyMap{"name": "future"})
+	if err := selector.Check(meta, did, VISA, future); err == nil {
+		t.Errorf("future document accepted")
+	}
+	doc := selector.Select(meta, did, VISA, []Document{current, future})
+	if doc == nil || doc.GetProperty("name") != "current" {
+		t.Errorf("selected: %v", doc)
+	}
+}
+
+func TestSelectorConcurrency(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	documents := make([]Document, 16)
+	for i := range documents {
+		documents[i] = signTestDocument(did, VISA, key, float64(1000+i), nil)
+	}
+	selector := NewDocumentSelector()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			doc := selector.Select(meta, did, VISA, documents)
+			if doc != documents[len(documents)-1] {
+				t.Errorf("selected: %v", doc)
+			}
+		}()
+	}
+	wg.Wait()
+}
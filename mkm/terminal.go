@@ -0,0 +1,132 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/dimchat/mkm-go/protocol"
+)
+
+/**
+ *  Terminal Visa
+ *
+ *      A user logged in on several devices publishes one visa for each
+ *      terminal, the owner is "name@address/terminal", so every device
+ *      can have its own encryption key:
+ *
+ *          {
+ *              "did"       : "moky@4WDfe3zZ4T7opFSi3iDAKiuTnUHjxmXekk/phone",
+ *              "type"      : "visa",
+ *              "data"      : "{JSON}",
+ *              "signature" : "{BASE64_ENCODE}"  // signed by the base meta key
+ *          }
+ *
+ *      The meta of "name@address" is shared by all terminals.
+ */
+
+// BaseID returns the ID without terminal
+func BaseID(did ID) ID {
+	if did.Terminal() == "" {
+		return did
+	}
+	return NewID(did.Name(), did.Address(), "")
+}
+
+// TerminalID returns the ID with terminal
+func TerminalID(did ID, terminal string) ID {
+	if did.Terminal() == terminal {
+		return did
+	}
+	return NewID(did.Name(), did.Address(), terminal)
+}
+
+// CheckTerminalVisa validates a terminal visa with the base meta
+//
+// Returns: nil if the document is a visa of a terminal of the user, signed by meta.key
+func CheckTerminalVisa(meta Meta, did ID, doc Document) error {
+	if doc == nil {
+		return errors.New("document empty")
+	} else if did == nil {
+		return errors.New("entity ID not provided")
+	} else if meta == nil {
+		return errors.New("meta not provided")
+	} else if !MetaMatchID(meta, did) {
+		return fmt.Errorf("meta not match ID: %s", did.String())
+	}
+	owner := documentID(doc)
+	if owner == nil {
+		return errors.New("document ID not found")
+	} else if owner.Terminal() == "" {
+		return fmt.Errorf("not a terminal document: %s", owner.String())
+	} else if !sameEntity(owner, did) {
+		return fmt.Errorf("document ID not match: %s, %s", owner.String(), did.String())
+	} else if docType := documentType(doc); docType != "" && docType != VISA {
+		return fmt.Errorf("not a visa: %s", docType)
+	} else if !doc.Verify(meta.PublicKey()) {
+		return fmt.Errorf("terminal visa not signed by meta key: %s", owner.String())
+	}
+	return nil
+}
+
+// SelectTerminalVisas picks the newest valid visa of each terminal with the default selector
+func SelectTerminalVisas(meta Meta, did ID, documents []Document) map[string]Visa {
+	return defaultDocumentSelector.SelectTerminalVisas(meta, did, documents)
+}
+
+// SelectTerminalVisas returns the newest valid visa for each terminal of the user
+//
+// Documents of the base ID (without terminal) are skipped.
+//
+// Returns: terminal => visa
+func (selector *DocumentSelector) SelectTerminalVisas(meta Meta, did ID, documents []Document) map[string]Visa {
+	if did == nil {
+		return map[string]Visa{}
+	}
+	groups := map[string][]Document{}
+	owners := map[string]ID{}
+	for _, doc := range documents {
+		if doc == nil {
+			continue
+		}
+		owner := documentID(doc)
+		if owner == nil || owner.Terminal() == "" || !sameEntity(owner, did) {
+			continue
+		}
+		terminal := owner.Terminal()
+		groups[terminal] = append(groups[terminal], doc)
+		owners[terminal] = owner
+	}
+	visas := make(map[string]Visa, len(groups))
+	for terminal, candidates := range groups {
+		doc := selector.Select(meta, owners[terminal], VISA, candidates)
+		if doc != nil {
+			visas[terminal] = VisaFromDocument(doc)
+		}
+	}
+	return visas
+}
@@ -0,0 +1,128 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"testing"
+
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func TestTerminalID(t *testing.T) {
+	meta := newTestMeta(newTestKey("moky-key"), "moky")
+	did := meta.ID("")
+	phone := TerminalID(did, "phone")
+	if phone.Terminal() != "phone" || !sameEntity(phone, did) {
+		t.Errorf("terminal ID: %s", phone.String())
+	}
+	if base := BaseID(phone); base.Terminal() != "" || base.String() != did.String() {
+		t.Errorf("base ID: %s", base.String())
+	}
+	if TerminalID(phone, "phone") != phone {
+		t.Errorf("terminal ID not reused")
+	}
+}
+
+func TestSelectTerminalFallback(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	phone := meta.ID("phone")
+	base := signTestDocument(did, VISA, key, 1000, StringKeyMap{"name": "base"})
+	other := signTestDocument(meta.ID("pad"), VISA, key, 3000, StringKeyMap{"name": "pad"})
+	// no visa for this terminal yet: fall back to the base visa
+	doc := SelectDocument(meta, phone, VISA, []Document{base, other})
+	if doc == nil || doc.GetProperty("name") != "base" {
+		t.Fatalf("selected: %v", doc)
+	}
+	// exact terminal is preferred, even when the base visa is newer
+	exact := signTestDocument(phone, VISA, key, 2000, StringKeyMap{"name": "phone"})
+	newer := signTestDocument(did, VISA, key, 5000, StringKeyMap{"name": "newer"})
+	doc = SelectDocument(meta, phone, VISA, []Document{base, newer, exact, other})
+	if doc == nil || doc.GetProperty("name") != "phone" {
+		t.Fatalf("selected: %v", doc)
+	}
+	// base ID never picks terminal visas
+	doc = SelectDocument(meta, did, VISA, []Document{exact, other, base})
+	if doc == nil || doc.GetProperty("name") != "base" {
+		t.Errorf("selected: %v", doc)
+	}
+}
+
+func TestCheckTerminalVisa(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	evilKey := newTestKey("evil-key")
+	evilMeta := newTestMeta(evilKey, "moky")
+	visa := signTestDocument(meta.ID("phone"), VISA, key, 1000, nil)
+	if err := CheckTerminalVisa(meta, did, visa); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name string
+		meta Meta
+		did  ID
+		doc  Document
+	}{
+		{"nil ID", meta, nil, visa},
+		{"nil meta", nil, did, visa},
+		{"nil document", meta, did, nil},
+		{"foreign meta", evilMeta, did, signTestDocument(meta.ID("phone"), VISA, evilKey, 1000, nil)},
+		{"base document", meta, did, signTestDocument(did, VISA, key, 1000, nil)},
+		{"not a visa", meta, did, signTestDocument(meta.ID("phone"), BULLETIN, key, 1000, nil)},
+		{"forged signature", meta, did, signTestDocument(meta.ID("phone"), VISA, evilKey, 1000, nil)},
+	}
+	for _, item := range cases {
+		if err := CheckTerminalVisa(item.meta, item.did, item.doc); err == nil {
+			t.Errorf("%s: expected error", item.name)
+		}
+	}
+}
+
+func TestSelectTerminalVisas(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	documents := []Document{
+		signTestDocument(did, VISA, key, 9000, StringKeyMap{"name": "base"}),
+		signTestDocument(meta.ID("phone"), VISA, key, 1000, StringKeyMap{"name": "phone-1"}),
+		signTestDocument(meta.ID("phone"), VISA, key, 2000, StringKeyMap{"name": "phone-2"}),
+		signTestDocument(meta.ID("pad"), VISA, key, 1500, StringKeyMap{"name": "pad"}),
+		signTestDocument(meta.ID("pc"), VISA, newTestKey("evil-key"), 1500, StringKeyMap{"name": "forged"}),
+	}
+	visas := SelectTerminalVisas(meta, did, documents)
+	if len(visas) != 2 {
+		t.Fatalf("visas: %v", visas)
+	}
+	if visas["phone"].Name() != "phone-2" || visas["pad"].Name() != "pad" {
+		t.Errorf("visas: %v", visas)
+	}
+	if visas = SelectTerminalVisas(meta, nil, documents); len(visas) != 0 {
+		t.Errorf("visas without ID: %v", visas)
+	}
+}
@@ -0,0 +1,95 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"testing"
+
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func TestDocumentBuilderValidity(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	doc, err := NewDocumentBuilder(did, VISA, key).
+		WithMeta(meta).
+		SetValidity(TimeFromFloat64(1000), TimeFromFloat64(2000)).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if TimeToFloat64(DocumentNotBefore(doc)) != 1000 || TimeToFloat64(DocumentExpires(doc)) != 2000 {
+		t.Errorf("validity: %v", doc.Properties())
+	}
+	visa := VisaFromDocument(doc).(*BaseVisa)
+	if visa.IsExpired(TimeFromFloat64(1999)) || !visa.IsExpired(TimeFromFloat64(2000)) {
+		t.Errorf("visa expiry not match")
+	}
+	for _, window := range [][2]float64{{2000, 1000}, {1000, 1000}} {
+		_, err = NewDocumentBuilder(did, VISA, key).
+			WithMeta(meta).
+			SetValidity(TimeFromFloat64(window[0]), TimeFromFloat64(window[1])).
+			Build()
+		if err == nil {
+			t.Errorf("empty window %v: expected error", window)
+		}
+	}
+	// open-ended
+	if _, err = NewDocumentBuilder(did, VISA, key).WithMeta(meta).SetValidity(nil, TimeFromFloat64(2000)).Build(); err != nil {
+		t.Errorf("open-ended window: %v", err)
+	}
+}
+
+func TestSelectorCheckValidity(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	did := meta.ID("")
+	expired := signTestDocument(did, VISA, key, 1000, StringKeyMap{"name": "expired", "expires": 1500})
+	pending := signTestDocument(did, VISA, key, 1100, StringKeyMap{"name": "pending", "not_before": 3000})
+	current := signTestDocument(did, VISA, key, 900, StringKeyMap{"name": "current", "expires": 5000})
+	malformed := signTestDocument(did, VISA, key, 1200, StringKeyMap{"name": "malformed", "expires": "never"})
+	documents := []Document{expired, pending, current, malformed}
+
+	selector := NewDocumentSelector()
+	selector.Now = func() Time {
+		return TimeFromFloat64(2000)
+	}
+	// validity not checked: the newest wins
+	if doc := selector.Select(meta, did, VISA, documents); doc == nil || doc.GetProperty("name") != "malformed" {
+		t.Fatalf("selected: %v", doc)
+	}
+	selector.CheckValidity = true
+	for _, doc := range []Document{expired, pending, malformed} {
+		if err := selector.Check(meta, did, VISA, doc); err == nil {
+			t.Errorf("%v: expected error", doc.GetProperty("name"))
+		}
+	}
+	if doc := selector.Select(meta, did, VISA, documents); doc == nil || doc.GetProperty("name") != "current" {
+		t.Errorf("selected: %v", doc)
+	}
+}
@@ -0,0 +1,121 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	. "github.com/dimchat/mkm-go/crypto"
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// BaseVisa wraps a user document with typed accessors
+type BaseVisa struct {
+	//Visa
+	Document
+}
+
+func NewBaseVisa(doc Document) *BaseVisa {
+	return &BaseVisa{
+		Document: doc,
+	}
+}
+
+// VisaFromDocument returns the document as Visa (wrapped if needed)
+func VisaFromDocument(doc Document) Visa {
+	if doc == nil {
+		return nil
+	} else if visa, ok := doc.(Visa); ok {
+		return visa
+	}
+	return NewBaseVisa(doc)
+}
+
+//-------- Visa
+
+// Override
+func (visa *BaseVisa) Name() string {
+	return documentString(visa, "name")
+}
+
+// Override
+func (visa *BaseVisa) SetName(name string) {
+	visa.SetProperty("name", name)
+}
+
+// Override
+func (visa *BaseVisa) PublicKey() EncryptKey {
+	info := visa.GetProperty("key")
+	if info == nil {
+		return nil
+	}
+	key := ParsePublicKey(info)
+	if pKey, ok := key.(EncryptKey); ok {
+		return pKey
+	}
+	//panic("visa key cannot encrypt")
+	return nil
+}
+
+// Override
+func (visa *BaseVisa) SetPublicKey(key EncryptKey) {
+	if key == nil {
+		visa.SetProperty("key", nil)
+	} else {
+		visa.SetProperty("key", key.Map())
+	}
+}
+
+// Override
+func (visa *BaseVisa) Avatar() TransportableFile {
+	info := visa.GetProperty("avatar")
+	if info == nil {
+		return nil
+	}
+	return ParseTransportableFile(info)
+}
+
+// Override
+func (visa *BaseVisa) SetAvatar(avatar TransportableFile) {
+	if avatar == nil {
+		visa.SetProperty("avatar", nil)
+	} else {
+		visa.SetProperty("avatar", avatar.Serialize())
+	}
+}
+
+// IsExpired checks the "expires" property
+func (visa *BaseVisa) IsExpired(now Time) bool {
+	return DocumentIsExpired(visa, now)
+}
+
+// documentString returns the property value as string
+func documentString(doc Document, name string) string {
+	value := doc.GetProperty(name)
+	if value == nil {
+		return ""
+	}
+	return FetchString(value)
+}
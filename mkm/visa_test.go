@@ -0,0 +1,113 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package mkm
+
+import (
+	"testing"
+
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func TestVisaFromDocument(t *testing.T) {
+	if VisaFromDocument(nil) != nil {
+		t.Errorf("visa from nil document")
+	}
+	doc := newTestDocument(VISA)
+	visa := VisaFromDocument(doc)
+	if base, ok := visa.(*BaseVisa); !ok || base.Document != doc {
+		t.Fatalf("document not wrapped: %#v", visa)
+	}
+	if VisaFromDocument(visa) != visa {
+		t.Errorf("visa wrapped again")
+	}
+	visa.SetName("Moky")
+	if visa.Name() != "Moky" || doc.GetProperty("name") != "Moky" {
+		t.Errorf("name: %q", visa.Name())
+	}
+}
+
+func TestVisaPublicKey(t *testing.T) {
+	visa := NewBaseVisa(newTestDocument(VISA))
+	if visa.PublicKey() != nil {
+		t.Errorf("public key without property")
+	}
+	visa.SetPublicKey(newTestEncryptKey("visa-key"))
+	pKey := visa.PublicKey()
+	if key, ok := pKey.(*testEncryptKey); !ok || key.name != "visa-key" {
+		t.Fatalf("public key: %#v", pKey)
+	}
+	// verify-only key: callers fall back to meta.key
+	visa.SetProperty("key", newTestKey("meta-key").Map())
+	if pKey = visa.PublicKey(); pKey != nil {
+		t.Errorf("verify-only key returned: %#v", pKey)
+	}
+	// unknown algorithm
+	visa.SetProperty("key", StringKeyMap{"algorithm": "UNKNOWN"})
+	if pKey = visa.PublicKey(); pKey != nil {
+		t.Errorf("unknown key returned: %#v", pKey)
+	}
+	visa.SetPublicKey(nil)
+	if visa.GetProperty("key") != nil || visa.PublicKey() != nil {
+		t.Errorf("public key not removed")
+	}
+}
+
+func TestVisaAvatar(t *testing.T) {
+	key := newTestKey("moky-key")
+	meta := newTestMeta(key, "moky")
+	doc := newTestDocument(VISA)
+	doc.Set("did", meta.ID("").String())
+	visa := NewBaseVisa(doc)
+	if visa.Avatar() != nil {
+		t.Errorf("avatar without property")
+	}
+	url := "https://cdn.example.com/avatar.png"
+	visa.SetAvatar(&testFile{info: StringKeyMap{"URL": url, "filename": "avatar.png"}})
+	visa.Sign(key)
+	// parsed again from the signed properties
+	if !visa.Verify(meta.PublicKey()) {
+		t.Fatalf("visa not verified")
+	}
+	avatar := VisaFromDocument(doc).Avatar()
+	if avatar == nil || avatar.URL().String() != url || avatar.Map()["filename"] != "avatar.png" {
+		t.Fatalf("avatar: %#v", avatar)
+	}
+	// URL string
+	visa.SetProperty("avatar", url)
+	if avatar = visa.Avatar(); avatar == nil || avatar.URL().String() != url {
+		t.Errorf("avatar from URL: %#v", avatar)
+	}
+	// malformed
+	visa.SetProperty("avatar", 42)
+	if avatar = visa.Avatar(); avatar != nil {
+		t.Errorf("malformed avatar: %#v", avatar)
+	}
+	visa.SetAvatar(nil)
+	if visa.GetProperty("avatar") != nil || visa.Avatar() != nil {
+		t.Errorf("avatar not removed")
+	}
+}
@@ -0,0 +1,339 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// FileCache is a bounded on-disk cache for downloaded file content
+//
+// Entries are evicted in LRU order when the total size exceeds the limit,
+// files are written atomically (temporary file + rename), and concurrent
+// fetching of the same key shares one loading call.
+type FileCache struct {
+	dir     string
+	maxSize int64
+
+	mutex   sync.Mutex
+	lru     *list.List               // front is the most recently used
+	entries map[string]*list.Element // key => *cacheEntry
+	total   int64
+
+	calls map[string]*cacheCall // loading calls in flight
+}
+
+type cacheEntry struct {
+	key  string
+	size int64
+}
+
+type cacheCall struct {
+	wg   sync.WaitGroup
+	data []byte
+	err  error
+}
+
+// NewFileCache creates a cache in the directory, existing files are indexed
+// (older modification time evicted first)
+//
+// Parameters:
+//   - dir: Cache directory
+//   - maxSize: Limit of total file size in bytes
+func NewFileCache(dir string, maxSize int64) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	cache := &FileCache{
+		dir:     dir,
+		maxSize: maxSize,
+		lru:     list.New(),
+		entries: map[string]*list.Element{},
+		calls:   map[string]*cacheCall{},
+	}
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []os.FileInfo
+	for _, item := range items {
+		if item.IsDir() || !isCacheKey(item.Name()) {
+			continue
+		}
+		if info, err := item.Info(); err == nil {
+			files = append(files, info)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().After(files[j].ModTime())
+	})
+	for _, info := range files {
+		entry := &cacheEntry{key: info.Name(), size: info.Size()}
+		cache.entries[entry.key] = cache.lru.PushBack(entry)
+		cache.total += entry.size
+	}
+	cache.mutex.Lock()
+	cache.evict()
+	cache.mutex.Unlock()
+	return cache, nil
+}
+
+// CacheKey builds the cache key of downloaded (maybe encrypted) data
+// from URL and content digest (optional)
+func CacheKey(url URL, digest string) string {
+	return cacheKey("data:", url, digest)
+}
+
+// PlainCacheKey builds the cache key of decrypted content,
+// which never equals the key of downloaded data for the same URL
+func PlainCacheKey(url URL, digest string) string {
+	return cacheKey("plain:", url, digest)
+}
+
+func cacheKey(namespace string, url URL, digest string) string {
+	hash := sha256.Sum256([]byte(namespace + url.String() + "\n" + digest))
+	return hex.EncodeToString(hash[:])
+}
+
+func isCacheKey(name string) bool {
+	if len(name) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(name)
+	return err == nil
+}
+
+func (cache *FileCache) path(key string) string {
+	return filepath.Join(cache.dir, key)
+}
+
+// Size returns the total size of cached files
+func (cache *FileCache) Size() int64 {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return cache.total
+}
+
+// Get returns the cached content
+func (cache *FileCache) Get(key string) ([]byte, bool) {
+	cache.mutex.Lock()
+	element, ok := cache.entries[key]
+	if ok {
+		cache.lru.MoveToFront(element)
+	}
+	cache.mutex.Unlock()
+	if !ok {
+		return nil, false
+	}
+	data, err := os.ReadFile(cache.path(key))
+	if err != nil {
+		// removed outside
+		cache.Remove(key)
+		return nil, false
+	}
+	return data, true
+}
+
+// Put stores the content, entries larger than the limit are not cached
+func (cache *FileCache) Put(key string, data []byte) error {
+	size := int64(len(data))
+	if size > cache.maxSize {
+		return nil
+	}
+	if err := writeFileAtomic(cache.path(key), data); err != nil {
+		return err
+	}
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if element, ok := cache.entries[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		cache.total += size - entry.size
+		entry.size = size
+		cache.lru.MoveToFront(element)
+	} else {
+		cache.entries[key] = cache.lru.PushFront(&cacheEntry{key: key, size: size})
+		cache.total += size
+	}
+	cache.evict()
+	return nil
+}
+
+// Remove deletes the cached content
+func (cache *FileCache) Remove(key string) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	if element, ok := cache.entries[key]; ok {
+		cache.removeElement(element)
+	}
+}
+
+// Fetch returns the cached content, or calls the loader and caches the result;
+// concurrent fetching of the same key waits for the first loading call
+func (cache *FileCache) Fetch(key string, loader func() ([]byte, error)) ([]byte, error) {
+	if data, ok := cache.Get(key); ok {
+		return data, nil
+	}
+	cache.mutex.Lock()
+	if call, ok := cache.calls[key]; ok {
+		cache.mutex.Unlock()
+		call.wg.Wait()
+		return call.data, call.err
+	}
+	call := &cacheCall{
+		// reported to waiters if the loader panics
+		err: errors.New("cache loader not finished"),
+	}
+	call.wg.Add(1)
+	cache.calls[key] = call
+	cache.mutex.Unlock()
+	defer func() {
+		cache.mutex.Lock()
+		delete(cache.calls, key)
+		cache.mutex.Unlock()
+		call.wg.Done()
+	}()
+
+	data, err := loader()
+	if err == nil {
+		// failed to cache is not an error for the caller
+		_ = cache.Put(key, data)
+	}
+	call.data, call.err = data, err
+	return data, err
+}
+
+// evict removes the least recently used entries (must be called with lock)
+func (cache *FileCache) evict() {
+	for cache.total > cache.maxSize {
+		element := cache.lru.Back()
+		if element == nil {
+			break
+		}
+		cache.removeElement(element)
+	}
+}
+
+func (cache *FileCache) removeElement(element *list.Element) {
+	entry := element.Value.(*cacheEntry)
+	cache.lru.Remove(element)
+	delete(cache.entries, entry.key)
+	cache.total -= entry.size
+	_ = os.Remove(cache.path(entry.key))
+}
+
+//
+//  Cached Downloader
+//
+
+// CachedDownloader caches the downloaded (maybe encrypted) data
+//
+// DownloadFile() keys the data by URL plus "cipher_digest", and verifies it with
+// "cipher_digest" & "cipher_size" before caching, so a truncated or replaced
+// download is never kept; Download() has no PNF, the data is keyed by URL only.
+type CachedDownloader struct {
+	//FileDownloader
+
+	Downloader Downloader
+	Cache      *FileCache
+}
+
+func NewCachedDownloader(downloader Downloader, cache *FileCache) *CachedDownloader {
+	return &CachedDownloader{
+		Downloader: downloader,
+		Cache:      cache,
+	}
+}
+
+// Override
+func (downloader *CachedDownloader) Download(remote URL) ([]byte, error) {
+	return downloader.Cache.Fetch(CacheKey(remote, ""), func() ([]byte, error) {
+		return downloader.Downloader.Download(remote)
+	})
+}
+
+// Override
+func (downloader *CachedDownloader) DownloadFile(pnf TransportableFile) ([]byte, error) {
+	remote := pnf.URL()
+	if remote == nil {
+		return nil, errors.New("file URL not found")
+	}
+	key := CacheKey(remote, pnf.GetString("cipher_digest", ""))
+	loader := func() ([]byte, error) {
+		data, err := downloader.Downloader.Download(remote)
+		if err != nil {
+			return nil, err
+		} else if err = VerifyCiphertext(pnf, data); err != nil {
+			// not cached
+			return nil, err
+		}
+		return data, nil
+	}
+	data, err := downloader.Cache.Fetch(key, loader)
+	if err == nil && VerifyCiphertext(pnf, data) != nil {
+		// cached file corrupted, download again
+		downloader.Cache.Remove(key)
+		data, err = downloader.Cache.Fetch(key, loader)
+	}
+	return data, err
+}
+
+// DownloadAndDecryptCached works as DownloadAndDecrypt(), and caches the decrypted content
+// keyed by PlainCacheKey() of URL plus "digest" (or "cipher_digest"); the downloader
+// can be a CachedDownloader over the same cache, their keys never collide
+//
+// Note: plaintext is stored on disk, use CachedDownloader to keep it encrypted
+func DownloadAndDecryptCached(pnf TransportableFile, downloader Downloader, cache *FileCache) ([]byte, error) {
+	if pnf == nil || pnf.URL() == nil {
+		// embedded data
+		return DownloadAndDecrypt(pnf, downloader)
+	}
+	digest := pnf.GetString("digest", "")
+	if digest == "" {
+		digest = pnf.GetString("cipher_digest", "")
+	}
+	key := PlainCacheKey(pnf.URL(), digest)
+	plaintext, err := cache.Fetch(key, func() ([]byte, error) {
+		return DownloadAndDecrypt(pnf, downloader)
+	})
+	if err != nil {
+		return nil, err
+	} else if err = VerifyPlaintext(pnf, plaintext); err != nil {
+		// cached file corrupted
+		cache.Remove(key)
+		return nil, err
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,320 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func testCacheKey(name string) string {
+	return CacheKey(ParseURL("https://cdn.example.com/"+name), "")
+}
+
+func TestFileCacheLRU(t *testing.T) {
+	cache, err := NewFileCache(t.TempDir(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b, c := testCacheKey("a"), testCacheKey("b"), testCacheKey("c")
+	_ = cache.Put(a, []byte("aaaa"))
+	_ = cache.Put(b, []byte("bbbb"))
+	// touch a, so b is the least recently used
+	if data, ok := cache.Get(a); !ok || string(data) != "aaaa" {
+		t.Fatalf("get a: %q, %v", data, ok)
+	}
+	_ = cache.Put(c, []byte("cccc"))
+	if _, ok := cache.Get(b); ok {
+		t.Errorf("b not evicted")
+	}
+	if _, ok := cache.Get(a); !ok {
+		t.Errorf("a evicted")
+	}
+	if cache.Size() != 8 {
+		t.Errorf("size: %d", cache.Size())
+	}
+	// too large to cache
+	_ = cache.Put(b, []byte("bbbbbbbbbbb"))
+	if _, ok := cache.Get(b); ok {
+		t.Errorf("entry larger than limit cached")
+	}
+	// replace
+	_ = cache.Put(a, []byte("A"))
+	if cache.Size() != 5 {
+		t.Errorf("size after replace: %d", cache.Size())
+	}
+	cache.Remove(a)
+	if _, ok := cache.Get(a); ok || cache.Size() != 4 {
+		t.Errorf("a not removed, size: %d", cache.Size())
+	}
+}
+
+func TestFileCacheReindex(t *testing.T) {
+	dir := t.TempDir()
+	cache, _ := NewFileCache(dir, 100)
+	key := testCacheKey("a")
+	_ = cache.Put(key, []byte("hello"))
+	// ignored files
+	_ = os.WriteFile(dir+"/notes.txt", []byte("x"), 0600)
+
+	again, err := NewFileCache(dir, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := again.Get(key); !ok || string(data) != "hello" || again.Size() != 5 {
+		t.Errorf("reindex: %q, %v, %d", data, ok, again.Size())
+	}
+	// file removed outside
+	_ = os.Remove(dir + "/" + key)
+	if _, ok := again.Get(key); ok || again.Size() != 0 {
+		t.Errorf("removed file still cached")
+	}
+}
+
+func TestCacheKeyNamespaces(t *testing.T) {
+	remote := ParseURL("https://cdn.example.com/a")
+	if CacheKey(remote, "") == PlainCacheKey(remote, "") {
+		t.Errorf("plain and data keys collide")
+	}
+	if CacheKey(remote, "") == CacheKey(remote, "sha256:00") {
+		t.Errorf("digest not in key")
+	}
+}
+
+func TestFileCacheFetchConcurrent(t *testing.T) {
+	cache, _ := NewFileCache(t.TempDir(), 1<<20)
+	var calls int32
+	release := make(chan struct{})
+	loader := func() ([]byte, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return []byte("data"), nil
+	}
+	key := testCacheKey("shared")
+	var wg sync.WaitGroup
+	errs := make(chan error, 32)
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			data, err := cache.Fetch(key, loader)
+			if err == nil && string(data) != "data" {
+				err = fmt.Errorf("unexpected data: %q", data)
+			}
+			errs <- err
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("loader called %d times", calls)
+	}
+	// errors are not cached
+	failed := testCacheKey("failed")
+	if _, err := cache.Fetch(failed, func() ([]byte, error) { return nil, errors.New("boom") }); err == nil {
+		t.Errorf("expected loader error")
+	}
+	if data, err := cache.Fetch(failed, func() ([]byte, error) { return []byte("ok"), nil }); err != nil || string(data) != "ok" {
+		t.Errorf("fetch after error: %q, %v", data, err)
+	}
+}
+
+func TestFileCacheFetchPanic(t *testing.T) {
+	cache, _ := NewFileCache(t.TempDir(), 1<<20)
+	key := testCacheKey("panic")
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		defer func() { _ = recover() }()
+		_, _ = cache.Fetch(key, func() ([]byte, error) {
+			close(started)
+			<-release
+			panic("loader crashed")
+		})
+	}()
+	<-started
+	done := make(chan error, 1)
+	go func() {
+		_, err := cache.Fetch(key, func() ([]byte, error) { return []byte("late"), nil })
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	select {
+	case <-done:
+		// waiter released (with error, or with its own loader)
+	case <-time.After(2 * time.Second):
+		t.Fatalf("waiter hangs after loader panic")
+	}
+	if data, err := cache.Fetch(key, func() ([]byte, error) { return []byte("again"), nil }); err != nil || data == nil {
+		t.Errorf("fetch after panic: %q, %v", data, err)
+	}
+}
+
+func TestDownloadAndDecryptCachedNested(t *testing.T) {
+	key := newTestKey("secret")
+	ciphertext := key.Encrypt([]byte("hello"), nil)
+	iv := fmt.Sprintf("%02x", byte(atomic.LoadUint32(&testKeyCounter)))
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		_, _ = w.Write(ciphertext)
+	}))
+	defer server.Close()
+
+	cache, _ := NewFileCache(t.TempDir(), 1<<20)
+	downloader := NewCachedDownloader(NewHTTPDownloader(server.Client()), cache)
+	// no "digest" or "cipher_digest": plain key must not collide with data key
+	pnf := newTestFile(nil, "hello.txt", ParseURL(server.URL+"/hello.txt"), key)
+	pnf.Set("IV", iv)
+
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < 3; i++ {
+			data, err := DownloadAndDecryptCached(pnf, downloader, cache)
+			if err == nil && string(data) != "hello" {
+				err = fmt.Errorf("plaintext: %q", data)
+			}
+			if err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("nested cache fetch deadlocked")
+	}
+	if hits != 1 {
+		t.Errorf("downloaded %d times", hits)
+	}
+	// both ciphertext and plaintext are cached
+	if cache.Size() != int64(len(ciphertext)+len("hello")) {
+		t.Errorf("cache size: %d", cache.Size())
+	}
+	if data, ok := cache.Get(PlainCacheKey(pnf.URL(), "")); !ok || string(data) != "hello" {
+		t.Errorf("plaintext not cached")
+	}
+}
+
+func TestCachedDownloaderVerifies(t *testing.T) {
+	key := newTestKey("secret")
+	plaintext := []byte("hello world")
+	ciphertext := key.Encrypt(plaintext, nil)
+	iv := fmt.Sprintf("%02x", byte(atomic.LoadUint32(&testKeyCounter)))
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			// truncated
+			_, _ = w.Write(ciphertext[:4])
+			return
+		}
+		_, _ = w.Write(ciphertext)
+	}))
+	defer server.Close()
+
+	cache, _ := NewFileCache(t.TempDir(), 1<<20)
+	downloader := NewCachedDownloader(NewHTTPDownloader(server.Client()), cache)
+	pnf := newTestFile(nil, "hello.txt", ParseURL(server.URL+"/hello.txt"), key)
+	pnf.Set("IV", iv)
+	AttachDigest(pnf, plaintext, ciphertext)
+
+	if _, err := DownloadAndDecrypt(pnf, downloader); !errors.Is(err, ErrSizeMismatch) {
+		t.Fatalf("corrupted download: %v", err)
+	}
+	if cache.Size() != 0 {
+		t.Fatalf("corrupted download cached: %d", cache.Size())
+	}
+	for i := 0; i < 2; i++ {
+		data, err := DownloadAndDecrypt(pnf, downloader)
+		if err != nil || string(data) != "hello world" {
+			t.Fatalf("download #%d: %q, %v", i, data, err)
+		}
+	}
+	if hits != 2 {
+		t.Errorf("downloaded %d times", hits)
+	}
+	digestKey := CacheKey(pnf.URL(), pnf.GetString("cipher_digest", ""))
+	if digestKey == CacheKey(pnf.URL(), "") {
+		t.Fatalf("cache key without digest")
+	}
+	// cached file corrupted on disk: downloaded again
+	if err := os.WriteFile(cache.path(digestKey), []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := DownloadAndDecrypt(pnf, downloader); err != nil || string(data) != "hello world" {
+		t.Fatalf("download after cache corrupted: %q, %v", data, err)
+	}
+	if hits != 3 {
+		t.Errorf("downloaded %d times", hits)
+	}
+}
+
+func TestCachedDownloaderReplacedFile(t *testing.T) {
+	var content atomic.Value
+	content.Store([]byte("version 1"))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(content.Load().([]byte))
+	}))
+	defer server.Close()
+
+	cache, _ := NewFileCache(t.TempDir(), 1<<20)
+	downloader := NewCachedDownloader(NewHTTPDownloader(server.Client()), cache)
+	url := ParseURL(server.URL + "/avatar.png")
+	first := newTestFile(nil, "avatar.png", url, nil)
+	AttachDigest(first, nil, []byte("version 1"))
+	if data, err := DownloadAndDecrypt(first, downloader); err != nil || string(data) != "version 1" {
+		t.Fatalf("first: %q, %v", data, err)
+	}
+	// same URL, new content: the old cache entry must not be used
+	content.Store([]byte("version 2"))
+	second := newTestFile(nil, "avatar.png", url, nil)
+	AttachDigest(second, nil, []byte("version 2"))
+	if data, err := DownloadAndDecrypt(second, downloader); err != nil || string(data) != "version 2" {
+		t.Fatalf("second: %q, %v", data, err)
+	}
+}
@@ -0,0 +1,215 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/dimchat/mkm-go/digest"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// CASScheme is the URL scheme for content-addressed storage
+//
+//	Format: "cas://{ALGORITHM}/{HEX_ENCODE}", e.g. "cas://sha256/2cf24dba5fb0a30e..."
+const CASScheme = "cas"
+
+var ErrContentAddress = errors.New("content address not match")
+
+// ContentAddress returns the content address of the (encrypted) file data
+func ContentAddress(data []byte) URL {
+	digest := ContentDigest(DigestAlgorithm, data)
+	if digest == "" {
+		return nil
+	}
+	pos := strings.IndexByte(digest, ':')
+	return &url.URL{
+		Scheme: CASScheme,
+		Host:   digest[:pos],
+		Path:   "/" + digest[pos+1:],
+	}
+}
+
+// ParseContentAddress splits the content address
+//
+// The algorithm must have a registered digester, so it's safe to be used as a path segment.
+//
+// Returns: algorithm name, hex encoded digest, false if not a content address
+func ParseContentAddress(remote URL) (string, string, bool) {
+	uri, err := toNetURL(remote)
+	if err != nil || !strings.EqualFold(uri.Scheme, CASScheme) {
+		return "", "", false
+	}
+	algorithm := strings.ToLower(uri.Host)
+	hash := strings.ToLower(strings.TrimPrefix(uri.Path, "/"))
+	if algorithm == "" || hash == "" || strings.Contains(hash, "/") {
+		return "", "", false
+	} else if _, err = hex.DecodeString(hash); err != nil {
+		return "", "", false
+	} else if !isAlphanumeric(algorithm) || GetDigester(algorithm) == nil {
+		return "", "", false
+	}
+	return algorithm, hash, true
+}
+
+// isAlphanumeric checks whether the algorithm name has only lowercase letters and digits
+func isAlphanumeric(str string) bool {
+	for _, ch := range str {
+		if !('a' <= ch && ch <= 'z' || '0' <= ch && ch <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyContentAddress checks the data against the content address
+func verifyContentAddress(remote URL, data []byte) error {
+	algorithm, hash, ok := ParseContentAddress(remote)
+	if !ok {
+		return fmt.Errorf("not a content address: %s", remote.String())
+	} else if !VerifyContentDigest(algorithm+":"+hash, data) {
+		return fmt.Errorf("%w: %s", ErrContentAddress, remote.String())
+	}
+	return nil
+}
+
+//
+//  Resolver
+//
+
+// CASResolver downloads content addresses from a base URL
+//
+//	"cas://sha256/{HEX}" => "{BaseURL}/sha256/{HEX}"
+//
+// The downloaded data is verified with the content address.
+type CASResolver struct {
+	//Downloader
+
+	BaseURL    string
+	Downloader Downloader // downloader for the resolved URL
+}
+
+func NewCASResolver(baseURL string, downloader Downloader) *CASResolver {
+	return &CASResolver{
+		BaseURL:    baseURL,
+		Downloader: downloader,
+	}
+}
+
+// Resolve converts the content address to download URL
+func (resolver *CASResolver) Resolve(remote URL) URL {
+	algorithm, hash, ok := ParseContentAddress(remote)
+	if !ok {
+		return nil
+	}
+	return ParseURL(strings.TrimRight(resolver.BaseURL, "/") + "/" + algorithm + "/" + hash)
+}
+
+// Override
+func (resolver *CASResolver) Download(remote URL) ([]byte, error) {
+	target := resolver.Resolve(remote)
+	if target == nil {
+		return nil, fmt.Errorf("not a content address: %s", remote.String())
+	}
+	data, err := resolver.Downloader.Download(target)
+	if err != nil {
+		return nil, err
+	} else if err = verifyContentAddress(remote, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+//
+//  Local Store
+//
+
+// LocalCASStore stores file data in a local directory by content address
+//
+//	Layout: "{dir}/{ALGORITHM}/{HEX}"
+//
+// Uploading identical data twice keeps only one object; the directory
+// can be served as the base URL of CASResolver.
+type LocalCASStore struct {
+	//Uploader
+	//Downloader
+
+	dir string
+}
+
+func NewLocalCASStore(dir string) *LocalCASStore {
+	return &LocalCASStore{
+		dir: dir,
+	}
+}
+
+func (store *LocalCASStore) Dir() string {
+	return store.dir
+}
+
+func (store *LocalCASStore) path(algorithm, hash string) string {
+	return filepath.Join(store.dir, algorithm, hash)
+}
+
+// Override
+func (store *LocalCASStore) Upload(data []byte, filename string) (URL, error) {
+	address := ContentAddress(data)
+	if address == nil {
+		return nil, fmt.Errorf("digest algorithm not support: %s", DigestAlgorithm)
+	}
+	algorithm, hash, _ := ParseContentAddress(address)
+	path := store.path(algorithm, hash)
+	if _, err := os.Stat(path); err == nil {
+		// already exists
+		return address, nil
+	} else if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	} else if err = writeFileAtomic(path, data); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// Override
+func (store *LocalCASStore) Download(remote URL) ([]byte, error) {
+	algorithm, hash, ok := ParseContentAddress(remote)
+	if !ok {
+		return nil, fmt.Errorf("not a content address: %s", remote.String())
+	}
+	data, err := os.ReadFile(store.path(algorithm, hash))
+	if err != nil {
+		return nil, err
+	} else if err = verifyContentAddress(remote, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
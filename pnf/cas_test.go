@@ -0,0 +1,125 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func TestContentAddress(t *testing.T) {
+	address := ContentAddress([]byte("abc"))
+	expected := "cas://sha256/ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	if address == nil || address.String() != expected {
+		t.Fatalf("content address: %v", address)
+	}
+	algorithm, hash, ok := ParseContentAddress(ParseURL(strings.ToUpper(expected)))
+	if !ok || algorithm != "sha256" || hash != expected[len("cas://sha256/"):] {
+		t.Errorf("parse: %s, %s, %v", algorithm, hash, ok)
+	}
+	for _, str := range []string{
+		"https://sha256/00ff",
+		"cas://sha256/",
+		"cas://sha256/xyz",
+		"cas://sha256/00/ff",
+		"cas:///00ff",
+		"cas://../00ff",
+		"cas://unknown/00ff",
+	} {
+		if _, _, ok := ParseContentAddress(ParseURL(str)); ok {
+			t.Errorf("expected failure for %s", str)
+		}
+	}
+}
+
+func TestLocalCASStore(t *testing.T) {
+	dir := t.TempDir()
+	store := NewLocalCASStore(dir)
+	address, err := store.Upload([]byte("hello"), "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// same content, same address
+	again, err := store.Upload([]byte("hello"), "b.txt")
+	if err != nil || again.String() != address.String() {
+		t.Errorf("upload again: %v, %v", again, err)
+	}
+	if data, err := store.Download(address); err != nil || string(data) != "hello" {
+		t.Errorf("download: %q, %v", data, err)
+	}
+	// corrupted on disk
+	_, hash, _ := ParseContentAddress(address)
+	if err = os.WriteFile(filepath.Join(dir, "sha256", hash), []byte("HELLO"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.Download(address); !errors.Is(err, ErrContentAddress) {
+		t.Errorf("corrupted content accepted: %v", err)
+	}
+	if _, err = store.Download(ParseURL("https://example.com/a")); err == nil {
+		t.Errorf("expected error for HTTP URL")
+	}
+}
+
+func TestCASResolver(t *testing.T) {
+	content := map[string]string{
+		"/cas/sha256/" + strings.TrimPrefix(ContentAddress([]byte("good")).String(), "cas://sha256/"): "good",
+		"/cas/sha256/" + strings.TrimPrefix(ContentAddress([]byte("evil")).String(), "cas://sha256/"): "tampered",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := content[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	resolver := NewCASResolver(server.URL+"/cas/", NewHTTPDownloader(server.Client()))
+	good := ContentAddress([]byte("good"))
+	if got := resolver.Resolve(good); got == nil || !strings.HasPrefix(got.String(), server.URL+"/cas/sha256/") {
+		t.Errorf("resolve: %v", got)
+	}
+	if data, err := resolver.Download(good); err != nil || string(data) != "good" {
+		t.Errorf("download: %q, %v", data, err)
+	}
+	if _, err := resolver.Download(ContentAddress([]byte("evil"))); !errors.Is(err, ErrContentAddress) {
+		t.Errorf("tampered content accepted: %v", err)
+	}
+	if _, err := resolver.Download(ContentAddress([]byte("missing"))); err == nil {
+		t.Errorf("expected error for missing content")
+	}
+	if resolver.Resolve(ParseURL("https://example.com/a")) != nil {
+		t.Errorf("resolved HTTP URL")
+	}
+}
@@ -0,0 +1,352 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	. "github.com/dimchat/mkm-go/crypto"
+	. "github.com/dimchat/mkm-go/digest"
+	. "github.com/dimchat/mkm-go/format"
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// DefaultChunkSize is the plaintext size of each chunk (4 MiB)
+const DefaultChunkSize = 4 << 20
+
+var ErrManifestMismatch = errors.New("chunk manifest digest not match")
+
+/**
+ *  Chunked PNF
+ *
+ *      {
+ *          "filename"     : "movie.mp4",
+ *          "content-type" : "video/mp4",               // detected from the first chunk
+ *          "key"          : {...},                     // password for key derivation
+ *          "size"         : 1073741824,                // total length of plaintext
+ *          "chunks"       : {
+ *              "algorithm"  : "AES-256-GCM-STREAM",
+ *              "chunk_size" : 4194304,             // plaintext size of each chunk
+ *              "size"       : 1073741824,
+ *              "salt"       : "{BASE64_ENCODE}",   // HKDF salt
+ *              "nonce"      : "{BASE64_ENCODE}",   // nonce prefix
+ *              "list"       : [
+ *                  {
+ *                      "URL"    : "https://...",
+ *                      "size"   : 4194320,         // length of ciphertext
+ *                      "digest" : "sha256:..."     // digest of ciphertext
+ *                  },
+ *                  ...
+ *              ]
+ *          },
+ *          "manifest_digest" : "sha256:..."        // digest of canonical JSON of "chunks"
+ *      }
+ */
+
+// ChunkInfo describes an encrypted chunk on CDN
+type ChunkInfo struct {
+	URL    URL
+	Size   int64  // length of ciphertext
+	Digest string // digest of ciphertext
+}
+
+// ChunkManifest describes the chunks of a large file
+type ChunkManifest struct {
+	Algorithm   string
+	ChunkSize   int
+	Size        int64 // total length of plaintext
+	Salt        []byte
+	NoncePrefix []byte
+	Chunks      []ChunkInfo
+}
+
+// Map returns the "chunks" value for PNF
+func (manifest *ChunkManifest) Map() StringKeyMap {
+	list := make([]any, len(manifest.Chunks))
+	for index, chunk := range manifest.Chunks {
+		list[index] = StringKeyMap{
+			"URL":    chunk.URL.String(),
+			"size":   chunk.Size,
+			"digest": chunk.Digest,
+		}
+	}
+	return StringKeyMap{
+		"algorithm":  manifest.Algorithm,
+		"chunk_size": manifest.ChunkSize,
+		"size":       manifest.Size,
+		"salt":       base64.StdEncoding.EncodeToString(manifest.Salt),
+		"nonce":      base64.StdEncoding.EncodeToString(manifest.NoncePrefix),
+		"list":       list,
+	}
+}
+
+// ManifestDigest computes the digest of canonical JSON of the manifest map
+func ManifestDigest(manifest StringKeyMap) string {
+	json := CanonicalJSONCoder{}.Encode(manifest)
+	return ContentDigest(DigestAlgorithm, []byte(json))
+}
+
+// GetChunkManifest parses the "chunks" in PNF, verified with "manifest_digest"
+//
+// Returns: nil manifest (with nil error) if the PNF is not chunked
+func GetChunkManifest(pnf TransportableFile) (*ChunkManifest, error) {
+	dict := FetchMap(pnf.Get("chunks"))
+	if dict == nil {
+		return nil, nil
+	}
+	digest := pnf.GetString("manifest_digest", "")
+	if digest == "" {
+		return nil, fmt.Errorf("%w: manifest digest not found", ErrManifestMismatch)
+	} else if !VerifyContentDigest(digest, []byte(CanonicalJSONCoder{}.Encode(dict))) {
+		return nil, ErrManifestMismatch
+	}
+	info := NewDictionary(dict)
+	manifest := &ChunkManifest{
+		Algorithm: info.GetString("algorithm", ""),
+		ChunkSize: info.GetInt("chunk_size", 0),
+		Size:      info.GetInt64("size", -1),
+	}
+	if manifest.Algorithm != StreamAlgorithm {
+		return nil, fmt.Errorf("chunk algorithm not support: %q", manifest.Algorithm)
+	}
+	var err error
+	if manifest.Salt, err = base64.StdEncoding.DecodeString(info.GetString("salt", "")); err != nil {
+		return nil, fmt.Errorf("chunk salt error: %w", err)
+	} else if manifest.NoncePrefix, err = base64.StdEncoding.DecodeString(info.GetString("nonce", "")); err != nil {
+		return nil, fmt.Errorf("chunk nonce error: %w", err)
+	}
+	list := FetchList(info.Get("list"))
+	if len(list) == 0 {
+		return nil, errors.New("chunk list empty")
+	}
+	for index, item := range list {
+		chunk := NewDictionary(FetchMap(item))
+		remote := ParseURL(chunk.GetString("URL", ""))
+		if remote == nil || !remote.IsAbs() {
+			return nil, fmt.Errorf("chunk URL error: #%d", index)
+		}
+		manifest.Chunks = append(manifest.Chunks, ChunkInfo{
+			URL:    remote,
+			Size:   chunk.GetInt64("size", -1),
+			Digest: chunk.GetString("digest", ""),
+		})
+	}
+	return manifest, nil
+}
+
+//
+//  Upload
+//
+
+// EncryptAndUploadChunked splits the file into chunks, encrypts each chunk
+// in STREAM mode and uploads them one by one
+//
+// The chunk key is derived from key.Data(), so the receiver decrypts
+// with Password() of the PNF.
+//
+// Parameters:
+//   - reader: Raw file content
+//   - filename: Name of the file; chunks are uploaded as "{filename}.{index}"
+//   - key: Symmetric key for key derivation
+//   - uploader: CDN uploader
+//   - chunkSize: Plaintext size of each chunk (DefaultChunkSize if not positive)
+//
+// Returns: Chunked PNF (without "URL" and "data")
+func EncryptAndUploadChunked(reader io.Reader, filename string, key SymmetricKey, uploader Uploader, chunkSize int) (TransportableFile, error) {
+	if key == nil {
+		return nil, errors.New("symmetric key not provided")
+	} else if uploader == nil {
+		return nil, errors.New("uploader not provided")
+	} else if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	manifest := &ChunkManifest{
+		Algorithm:   StreamAlgorithm,
+		ChunkSize:   chunkSize,
+		Salt:        make([]byte, streamSaltSize),
+		NoncePrefix: make([]byte, streamNoncePrefixSize),
+	}
+	if _, err := rand.Read(manifest.Salt); err != nil {
+		return nil, err
+	} else if _, err = rand.Read(manifest.NoncePrefix); err != nil {
+		return nil, err
+	}
+	sc, err := newStreamCipher(keyMaterial(key), manifest.Salt, manifest.NoncePrefix)
+	if err != nil {
+		return nil, err
+	}
+	// read one chunk ahead to find the last one
+	current, err := readChunk(reader, chunkSize)
+	contentType := DetectMimeType(filename, current)
+	for index := 0; err == nil; index++ {
+		var next []byte
+		if len(current) == chunkSize {
+			if next, err = readChunk(reader, chunkSize); err != nil {
+				break
+			}
+		}
+		last := len(next) == 0
+		ciphertext := sc.seal(index, last, current)
+		var remote URL
+		remote, err = uploader.Upload(ciphertext, fmt.Sprintf("%s.%d", filename, index))
+		if err != nil {
+			break
+		} else if remote == nil {
+			err = errors.New("upload URL not returned")
+			break
+		}
+		manifest.Chunks = append(manifest.Chunks, ChunkInfo{
+			URL:    remote,
+			Size:   int64(len(ciphertext)),
+			Digest: ContentDigest(DigestAlgorithm, ciphertext),
+		})
+		manifest.Size += int64(len(current))
+		if last {
+			break
+		}
+		current = next
+	}
+	if err != nil {
+		return nil, err
+	}
+	chunks := manifest.Map()
+	pnf := CreateTransportableFile(nil, filename, nil, key)
+	SyncContentType(pnf, contentType)
+	pnf.Set("size", manifest.Size)
+	pnf.Set("chunks", chunks)
+	pnf.Set("manifest_digest", ManifestDigest(chunks))
+	return pnf, nil
+}
+
+// readChunk reads up to size bytes (empty at the end of reader)
+func readChunk(reader io.Reader, size int) ([]byte, error) {
+	buffer := make([]byte, size)
+	n, err := io.ReadFull(reader, buffer)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return buffer[:n], err
+}
+
+func keyMaterial(key CryptographyKey) []byte {
+	if key == nil {
+		return nil
+	}
+	ted := key.Data()
+	if ted == nil {
+		return nil
+	}
+	return ted.Bytes()
+}
+
+//
+//  Download
+//
+
+// ChunkedFile downloads and decrypts chunks independently
+//
+// Usage:
+//
+//	file, err := OpenChunkedFile(pnf, downloader)
+//	next, err := file.WriteTo(w, 0)  // resume from next after failure
+type ChunkedFile struct {
+	manifest   *ChunkManifest
+	cipher     *streamCipher
+	downloader Downloader
+}
+
+// OpenChunkedFile verifies the manifest of the chunked PNF, and derives the chunk key from Password()
+func OpenChunkedFile(pnf TransportableFile, downloader Downloader) (*ChunkedFile, error) {
+	if pnf == nil {
+		return nil, errors.New("file not provided")
+	} else if downloader == nil {
+		return nil, errors.New("downloader not provided")
+	}
+	manifest, err := GetChunkManifest(pnf)
+	if err != nil {
+		return nil, err
+	} else if manifest == nil {
+		return nil, errors.New("not a chunked file")
+	}
+	sc, err := newStreamCipher(keyMaterial(pnf.Password()), manifest.Salt, manifest.NoncePrefix)
+	if err != nil {
+		return nil, err
+	}
+	return &ChunkedFile{
+		manifest:   manifest,
+		cipher:     sc,
+		downloader: downloader,
+	}, nil
+}
+
+func (file *ChunkedFile) Manifest() *ChunkManifest {
+	return file.manifest
+}
+
+// Count returns the number of chunks
+func (file *ChunkedFile) Count() int {
+	return len(file.manifest.Chunks)
+}
+
+// Chunk downloads, verifies and decrypts the chunk at index
+func (file *ChunkedFile) Chunk(index int) ([]byte, error) {
+	chunks := file.manifest.Chunks
+	if index < 0 || index >= len(chunks) {
+		return nil, fmt.Errorf("chunk index out of range: %d", index)
+	}
+	chunk := chunks[index]
+	ciphertext, err := file.downloader.Download(chunk.URL)
+	if err != nil {
+		return nil, err
+	} else if chunk.Size >= 0 && chunk.Size != int64(len(ciphertext)) {
+		return nil, fmt.Errorf("%w: chunk #%d", ErrSizeMismatch, index)
+	} else if chunk.Digest != "" && !VerifyContentDigest(chunk.Digest, ciphertext) {
+		return nil, fmt.Errorf("%w: chunk #%d", ErrDigestMismatch, index)
+	}
+	last := index == len(chunks)-1
+	return file.cipher.open(index, last, ciphertext)
+}
+
+// WriteTo writes the plaintext of chunks to w, starting from chunk at index
+//
+// Returns: index of the next chunk to write (Count() when finished),
+// which can be used to resume after failure
+func (file *ChunkedFile) WriteTo(w io.Writer, start int) (int, error) {
+	for index := start; index < file.Count(); index++ {
+		plaintext, err := file.Chunk(index)
+		if err != nil {
+			return index, err
+		} else if _, err = w.Write(plaintext); err != nil {
+			return index, err
+		}
+	}
+	return file.Count(), nil
+}
@@ -0,0 +1,97 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"errors"
+	"fmt"
+
+	. "github.com/dimchat/mkm-go/digest"
+	. "github.com/dimchat/mkm-go/protocol"
+)
+
+// DigestAlgorithm is the default algorithm for content digest
+var DigestAlgorithm = "sha256"
+
+var (
+	ErrDigestMismatch = errors.New("file digest not match")
+	ErrSizeMismatch   = errors.New("file size not match")
+)
+
+// AttachDigest sets the integrity fields into the PNF
+//
+//	Fields:
+//	    "digest"        : "sha256:{HEX_ENCODE}", // digest of plaintext
+//	    "size"          : 1024,                  // length of plaintext
+//	    "cipher_digest" : "sha256:{HEX_ENCODE}", // digest of ciphertext on CDN
+//	    "cipher_size"   : 1040                   // length of ciphertext on CDN
+//
+// Parameters:
+//   - pnf: Transportable file
+//   - plaintext: Raw file content (nil to skip)
+//   - ciphertext: Encrypted file data uploaded to CDN (nil to skip)
+func AttachDigest(pnf TransportableFile, plaintext, ciphertext []byte) {
+	if plaintext != nil {
+		pnf.Set("digest", ContentDigest(DigestAlgorithm, plaintext))
+		pnf.Set("size", len(plaintext))
+	}
+	if ciphertext != nil {
+		pnf.Set("cipher_digest", ContentDigest(DigestAlgorithm, ciphertext))
+		pnf.Set("cipher_size", len(ciphertext))
+	}
+}
+
+// VerifyPlaintext checks the plaintext with "digest" and "size" fields (if exist)
+func VerifyPlaintext(pnf TransportableFile, plaintext []byte) error {
+	return verifyContent(pnf, "digest", "size", plaintext)
+}
+
+// VerifyCiphertext checks the downloaded data with "cipher_digest" and "cipher_size" fields (if exist)
+func VerifyCiphertext(pnf TransportableFile, ciphertext []byte) error {
+	return verifyContent(pnf, "cipher_digest", "cipher_size", ciphertext)
+}
+
+func verifyContent(pnf TransportableFile, digestKey, sizeKey string, data []byte) error {
+	if pnf.Contains(sizeKey) {
+		size := pnf.GetInt64(sizeKey, -1)
+		if size != int64(len(data)) {
+			return fmt.Errorf("%w: %s=%d, got %d", ErrSizeMismatch, sizeKey, size, len(data))
+		}
+	}
+	value := pnf.GetString(digestKey, "")
+	if value == "" {
+		return nil
+	}
+	algorithm, _, ok := ParseContentDigest(value)
+	if !ok {
+		return fmt.Errorf("%w: malformed %s %q", ErrDigestMismatch, digestKey, value)
+	} else if GetDigester(algorithm) == nil {
+		return fmt.Errorf("%w: %s algorithm not support: %s", ErrDigestMismatch, digestKey, algorithm)
+	} else if !VerifyContentDigest(value, data) {
+		return fmt.Errorf("%w: %s=%s", ErrDigestMismatch, digestKey, value)
+	}
+	return nil
+}
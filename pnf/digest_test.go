@@ -0,0 +1,82 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAttachAndVerifyDigest(t *testing.T) {
+	pnf := newTestFile(nil, "a.txt", nil, nil)
+	AttachDigest(pnf, []byte("plain"), []byte("cipher!"))
+	if pnf.GetInt("size", 0) != 5 || pnf.GetInt("cipher_size", 0) != 7 {
+		t.Errorf("sizes: %v", pnf.Map())
+	}
+	if err := VerifyPlaintext(pnf, []byte("plain")); err != nil {
+		t.Errorf("plaintext: %v", err)
+	}
+	if err := VerifyCiphertext(pnf, []byte("cipher!")); err != nil {
+		t.Errorf("ciphertext: %v", err)
+	}
+	if err := VerifyPlaintext(pnf, []byte("plai")); !errors.Is(err, ErrSizeMismatch) {
+		t.Errorf("expected size mismatch: %v", err)
+	}
+	if err := VerifyPlaintext(pnf, []byte("PLAIN")); !errors.Is(err, ErrDigestMismatch) {
+		t.Errorf("expected digest mismatch: %v", err)
+	}
+}
+
+func TestVerifyDigestOptional(t *testing.T) {
+	pnf := newTestFile(nil, "a.txt", nil, nil)
+	if err := VerifyPlaintext(pnf, []byte("anything")); err != nil {
+		t.Errorf("no integrity fields: %v", err)
+	}
+	pnf.Set("digest", "sha256:zz")
+	if err := VerifyPlaintext(pnf, nil); !errors.Is(err, ErrDigestMismatch) {
+		t.Errorf("malformed digest: %v", err)
+	}
+	pnf.Set("digest", "whirlpool:00ff")
+	if err := VerifyPlaintext(pnf, nil); !errors.Is(err, ErrDigestMismatch) {
+		t.Errorf("unknown algorithm: %v", err)
+	}
+}
+
+func TestEmbeddedDataIntegrity(t *testing.T) {
+	pnf := newTestFile(nil, "a.txt", nil, nil)
+	if _, err := DownloadAndDecrypt(pnf, nil); err == nil {
+		t.Errorf("expected error without data and URL")
+	}
+	AttachDigest(pnf, []byte("hello"), nil)
+	pnf.SetData(testTED("hello"))
+	if data, err := DownloadAndDecrypt(pnf, nil); err != nil || string(data) != "hello" {
+		t.Errorf("embedded data: %q, %v", data, err)
+	}
+	pnf.SetData(testTED("hellO"))
+	if _, err := DownloadAndDecrypt(pnf, nil); !errors.Is(err, ErrDigestMismatch) {
+		t.Errorf("expected digest mismatch: %v", err)
+	}
+}
@@ -0,0 +1,350 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// Downloader defines the interface for fetching (encrypted) file data from CDN
+type Downloader interface {
+
+	// Download fetches the file data
+	//
+	// Parameters:
+	//   - url: Download URL
+	// Returns: File data (maybe ciphertext)
+	Download(url URL) ([]byte, error)
+}
+
+// FileDownloader is a Downloader which knows the PNF being downloaded,
+// so it can use the integrity fields (e.g., caching by "cipher_digest")
+type FileDownloader interface {
+	Downloader
+
+	// DownloadFile fetches the file data from pnf.URL()
+	//
+	// Returns: File data (maybe ciphertext)
+	DownloadFile(pnf TransportableFile) ([]byte, error)
+}
+
+// DownloadAndDecrypt returns the plaintext content of the PNF
+//
+//	Steps:
+//	    1. if no "URL", returns the embedded "data";
+//	    2. downloads the ciphertext from "URL" (with DownloadFile() for FileDownloader),
+//	       and verifies it with "cipher_digest" & "cipher_size";
+//	    3. decrypts with Password() and the PNF map as params
+//	       (returns ciphertext directly when no password);
+//	    4. verifies the plaintext with "digest" & "size".
+//
+// Integrity fields are optional, but a mismatch is rejected with
+// ErrDigestMismatch or ErrSizeMismatch.
+//
+// Parameters:
+//   - pnf: Transportable file
+//   - downloader: CDN downloader
+//
+// Returns: Plaintext file content
+func DownloadAndDecrypt(pnf TransportableFile, downloader Downloader) ([]byte, error) {
+	if pnf == nil {
+		return nil, errors.New("file not provided")
+	}
+	remote := pnf.URL()
+	if remote == nil {
+		// embedded data
+		ted := pnf.Data()
+		if ted == nil {
+			return nil, errors.New("file data and URL not found")
+		}
+		plaintext := ted.Bytes()
+		if err := VerifyPlaintext(pnf, plaintext); err != nil {
+			return nil, err
+		}
+		return plaintext, nil
+	} else if downloader == nil {
+		return nil, errors.New("downloader not provided")
+	}
+	var ciphertext []byte
+	var err error
+	if fd, ok := downloader.(FileDownloader); ok {
+		ciphertext, err = fd.DownloadFile(pnf)
+	} else {
+		ciphertext, err = downloader.Download(remote)
+	}
+	if err != nil {
+		return nil, err
+	} else if err = VerifyCiphertext(pnf, ciphertext); err != nil {
+		return nil, err
+	}
+	plaintext := ciphertext
+	if password := pnf.Password(); password != nil {
+		plaintext = password.Decrypt(ciphertext, pnf.Map())
+		if plaintext == nil {
+			return nil, fmt.Errorf("failed to decrypt file: %s", remote.String())
+		}
+	}
+	if err = VerifyPlaintext(pnf, plaintext); err != nil {
+		return nil, err
+	}
+	return plaintext, nil
+}
+
+//
+//  Local files
+//
+
+// LocalDownloader reads "file://" URLs inside a root directory
+//
+// PNF URLs come from peers, so paths resolved outside the root
+// (including by symbolic links) are rejected; use the directory of
+// LocalUploader as the root.
+type LocalDownloader struct {
+	//Downloader
+
+	dir string
+}
+
+func NewLocalDownloader(dir string) *LocalDownloader {
+	return &LocalDownloader{
+		dir: dir,
+	}
+}
+
+// Dir returns the root directory
+func (downloader *LocalDownloader) Dir() string {
+	return downloader.dir
+}
+
+// Override
+func (downloader *LocalDownloader) Download(remote URL) ([]byte, error) {
+	uri, err := toNetURL(remote)
+	if err != nil {
+		return nil, err
+	} else if !strings.EqualFold(uri.Scheme, "file") || uri.Path == "" {
+		return nil, fmt.Errorf("not a file URL: %s", remote.String())
+	} else if uri.Host != "" && !strings.EqualFold(uri.Host, "localhost") {
+		return nil, fmt.Errorf("not a local file URL: %s", remote.String())
+	}
+	path, err := downloader.resolve(filepath.FromSlash(uri.Path))
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(path)
+}
+
+// resolve returns the real path of the file, error if it's outside the root directory
+func (downloader *LocalDownloader) resolve(path string) (string, error) {
+	if downloader.dir == "" {
+		return "", errors.New("local root directory not set")
+	}
+	root, err := filepath.Abs(downloader.dir)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("file path not absolute: %s", path)
+	}
+	// check the path before and after resolving symbolic links
+	target := filepath.Clean(path)
+	if abs, err := filepath.Abs(downloader.dir); err != nil || !isSubPath(abs, target) && !isSubPath(root, target) {
+		return "", fmt.Errorf("file path outside %s: %s", downloader.dir, path)
+	}
+	target, err = filepath.EvalSymlinks(target)
+	if err != nil {
+		return "", err
+	} else if !isSubPath(root, target) {
+		return "", fmt.Errorf("file path outside %s: %s", downloader.dir, path)
+	}
+	return target, nil
+}
+
+func isSubPath(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || filepath.IsAbs(rel) {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+//
+//  HTTP(S)
+//
+
+// DefaultMaxDownloadSize limits the response body length of HTTPDownloader (64 MiB)
+const DefaultMaxDownloadSize int64 = 64 << 20
+
+// HTTPDownloader fetches "http://" and "https://" URLs
+//
+// Client can be injected for custom transport, timeout or tests (httptest.Server.Client());
+// http.DefaultClient is used when it's nil.
+type HTTPDownloader struct {
+	//Downloader
+
+	Client *http.Client
+
+	// MaxSize limits the response body length
+	// (0 means DefaultMaxDownloadSize, negative means no limit)
+	MaxSize int64
+}
+
+func NewHTTPDownloader(client *http.Client) *HTTPDownloader {
+	return &HTTPDownloader{
+		Client:  client,
+		MaxSize: DefaultMaxDownloadSize,
+	}
+}
+
+func (downloader *HTTPDownloader) maxSize() int64 {
+	if downloader.MaxSize == 0 {
+		return DefaultMaxDownloadSize
+	}
+	return downloader.MaxSize
+}
+
+// Override
+func (downloader *HTTPDownloader) Download(remote URL) ([]byte, error) {
+	return downloader.DownloadContext(context.Background(), remote)
+}
+
+// DownloadContext fetches the URL with context
+func (downloader *HTTPDownloader) DownloadContext(ctx context.Context, remote URL) ([]byte, error) {
+	uri, err := toNetURL(remote)
+	if err != nil {
+		return nil, err
+	}
+	scheme := strings.ToLower(uri.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return nil, fmt.Errorf("not a HTTP URL: %s", remote.String())
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, uri.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	client := downloader.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download %s: %s", remote.String(), response.Status)
+	}
+	limit := downloader.maxSize()
+	var body io.Reader = response.Body
+	if limit > 0 {
+		if response.ContentLength > limit {
+			return nil, fmt.Errorf("file too large: %d > %d", response.ContentLength, limit)
+		}
+		body = io.LimitReader(body, limit+1)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	} else if limit > 0 && int64(len(data)) > limit {
+		return nil, fmt.Errorf("file too large: > %d", limit)
+	}
+	return data, nil
+}
+
+//
+//  Dispatcher
+//
+
+// SchemeDownloader dispatches download requests by URL scheme
+//
+// The default one handles "http" and "https" only; local files must be
+// enabled explicitly with a root directory:
+//
+//	downloader.SetDownloader("file", NewLocalDownloader(uploader.Dir()))
+type SchemeDownloader struct {
+	//Downloader
+
+	downloaders map[string]Downloader
+}
+
+func NewSchemeDownloader() *SchemeDownloader {
+	web := NewHTTPDownloader(nil)
+	return &SchemeDownloader{
+		downloaders: map[string]Downloader{
+			"http":  web,
+			"https": web,
+		},
+	}
+}
+
+// SetDownloader registers a downloader for the scheme (nil to remove)
+func (downloader *SchemeDownloader) SetDownloader(scheme string, delegate Downloader) {
+	scheme = strings.ToLower(scheme)
+	if delegate == nil {
+		delete(downloader.downloaders, scheme)
+	} else {
+		downloader.downloaders[scheme] = delegate
+	}
+}
+
+func (downloader *SchemeDownloader) GetDownloader(scheme string) Downloader {
+	return downloader.downloaders[strings.ToLower(scheme)]
+}
+
+// Override
+func (downloader *SchemeDownloader) Download(remote URL) ([]byte, error) {
+	uri, err := toNetURL(remote)
+	if err != nil {
+		return nil, err
+	}
+	delegate := downloader.GetDownloader(uri.Scheme)
+	if delegate == nil {
+		return nil, fmt.Errorf("URL scheme not support: %s", remote.String())
+	}
+	return delegate.Download(remote)
+}
+
+func toNetURL(remote URL) (*url.URL, error) {
+	if remote == nil {
+		return nil, errors.New("URL not provided")
+	} else if uri, ok := remote.(*url.URL); ok {
+		return uri, nil
+	}
+	return url.Parse(remote.String())
+}
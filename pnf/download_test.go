@@ -0,0 +1,191 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func TestLocalUploadAndDownload(t *testing.T) {
+	dir := t.TempDir()
+	uploader := NewLocalUploader(dir)
+	key := newTestKey("secret")
+	data := []byte("hello world")
+	pnf, err := EncryptAndUpload(data, "hello.txt", key, uploader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pnf.GetString("IV", "") == "" {
+		t.Errorf("extra params not copied")
+	}
+	if pnf.ContentType() != "text/plain" {
+		t.Errorf("content type: %s", pnf.ContentType())
+	}
+	downloader := NewSchemeDownloader()
+	downloader.SetDownloader("file", NewLocalDownloader(uploader.Dir()))
+	plaintext, err := DownloadAndDecrypt(pnf, downloader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(plaintext, data) {
+		t.Errorf("plaintext: %q", plaintext)
+	}
+}
+
+func TestLocalDownloaderRoot(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.txt")
+	if err := os.WriteFile(secret, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	inside := filepath.Join(root, "ok.txt")
+	if err := os.WriteFile(inside, []byte("ok"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "link.txt")
+	if err := os.Symlink(secret, link); err != nil {
+		t.Skipf("symlink not supported: %v", err)
+	}
+	downloader := NewLocalDownloader(root)
+	fileURL := func(path string) URL {
+		return &url.URL{Scheme: "file", Path: filepath.ToSlash(path)}
+	}
+	if data, err := downloader.Download(fileURL(inside)); err != nil || string(data) != "ok" {
+		t.Errorf("file inside root: %q, %v", data, err)
+	}
+	rejected := []URL{
+		fileURL(secret),
+		fileURL(root + "/../" + filepath.Base(outside) + "/secret.txt"),
+		fileURL(link),
+		ParseURL("file://example.com" + filepath.ToSlash(inside)),
+		ParseURL("http://localhost/ok.txt"),
+		ParseURL("file:ok.txt"),
+	}
+	for _, remote := range rejected {
+		if data, err := downloader.Download(remote); err == nil {
+			t.Errorf("expected error for %s, got %q", remote.String(), data)
+		}
+	}
+	if _, err := NewLocalDownloader("").Download(fileURL(inside)); err == nil {
+		t.Errorf("expected error for empty root")
+	}
+}
+
+func TestSchemeDownloaderDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hostname")
+	if err := os.WriteFile(path, []byte("host"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	downloader := NewSchemeDownloader()
+	if downloader.GetDownloader("file") != nil {
+		t.Fatalf("file scheme enabled by default")
+	}
+	if _, err := downloader.Download(&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}); err == nil {
+		t.Errorf("file URL downloaded by default")
+	}
+	if downloader.GetDownloader("HTTPS") == nil {
+		t.Errorf("https scheme not enabled")
+	}
+}
+
+func TestHTTPDownloader(t *testing.T) {
+	body := strings.Repeat("x", 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/file":
+			_, _ = w.Write([]byte(body))
+		case "/chunked":
+			// no Content-Length
+			w.(http.Flusher).Flush()
+			_, _ = w.Write([]byte(body))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	downloader := NewHTTPDownloader(server.Client())
+	if downloader.MaxSize != DefaultMaxDownloadSize {
+		t.Errorf("default max size: %d", downloader.MaxSize)
+	}
+	data, err := downloader.Download(ParseURL(server.URL + "/file"))
+	if err != nil || string(data) != body {
+		t.Errorf("download: %d bytes, %v", len(data), err)
+	}
+	if _, err = downloader.Download(ParseURL(server.URL + "/missing")); err == nil {
+		t.Errorf("expected error for 404")
+	}
+	if _, err = downloader.Download(ParseURL("ftp://example.com/file")); err == nil {
+		t.Errorf("expected error for ftp URL")
+	}
+	// size limits
+	downloader.MaxSize = 999
+	for _, path := range []string{"/file", "/chunked"} {
+		if _, err = downloader.Download(ParseURL(server.URL + path)); err == nil {
+			t.Errorf("expected error for %s larger than limit", path)
+		}
+	}
+	downloader.MaxSize = 1000
+	if data, err = downloader.Download(ParseURL(server.URL + "/chunked")); err != nil || len(data) != 1000 {
+		t.Errorf("download at limit: %d bytes, %v", len(data), err)
+	}
+	downloader.MaxSize = -1
+	if data, err = downloader.Download(ParseURL(server.URL + "/file")); err != nil || len(data) != 1000 {
+		t.Errorf("download without limit: %d bytes, %v", len(data), err)
+	}
+	zero := &HTTPDownloader{Client: server.Client()}
+	if zero.maxSize() != DefaultMaxDownloadSize {
+		t.Errorf("zero max size: %d", zero.maxSize())
+	}
+}
+
+func TestDownloadAndDecryptIntegrity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("tampered"))
+	}))
+	defer server.Close()
+
+	key := newTestKey("secret")
+	pnf := newTestFile(nil, "a.bin", ParseURL(server.URL+"/a.bin"), key)
+	pnf.Set("IV", "01")
+	AttachDigest(pnf, nil, []byte("original"))
+	if _, err := DownloadAndDecrypt(pnf, NewHTTPDownloader(server.Client())); err == nil {
+		t.Errorf("expected integrity error")
+	}
+	if _, err := DownloadAndDecrypt(pnf, nil); err == nil {
+		t.Errorf("expected error without downloader")
+	}
+}
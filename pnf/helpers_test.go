@@ -0,0 +1,236 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"sync/atomic"
+
+	. "github.com/dimchat/mkm-go/crypto"
+	. "github.com/dimchat/mkm-go/format"
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+/**
+ *  Test fixtures: PNF and symmetric key implementations
+ */
+
+type testBase64Coder struct{}
+
+func (testBase64Coder) Encode(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func (testBase64Coder) Decode(str string) []byte {
+	data, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func init() {
+	SetBase64Coder(testBase64Coder{})
+	SetTransportableFileHelper(testFileHelper{})
+}
+
+// testFile is a minimal TransportableFile
+type testFile struct {
+	*Dictionary
+
+	data     TransportableData
+	url      URL
+	password DecryptKey
+}
+
+func newTestFile(data TransportableData, filename string, url URL, password DecryptKey) *testFile {
+	file := &testFile{
+		Dictionary: NewDictionary(nil),
+		data:       data,
+		url:        url,
+		password:   password,
+	}
+	if filename != "" {
+		file.Set("filename", filename)
+	}
+	if url != nil {
+		file.Set("URL", url.String())
+	}
+	return file
+}
+
+// Override
+func (file *testFile) Data() TransportableData {
+	return file.data
+}
+
+// Override
+func (file *testFile) SetData(data TransportableData) {
+	file.data = data
+}
+
+// Override
+func (file *testFile) Filename() string {
+	return file.GetString("filename", "")
+}
+
+// Override
+func (file *testFile) SetFilename(filename string) {
+	file.Set("filename", filename)
+}
+
+// Override
+func (file *testFile) ContentType() string {
+	if mimeType := file.GetString("content-type", ""); mimeType != "" {
+		return mimeType
+	} else if file.data != nil {
+		return file.data.MimeType()
+	}
+	return ""
+}
+
+// Override
+func (file *testFile) SetContentType(mimeType string) {
+	file.Set("content-type", mimeType)
+}
+
+// Override
+func (file *testFile) URL() URL {
+	return file.url
+}
+
+// Override
+func (file *testFile) SetURL(url URL) {
+	file.url = url
+}
+
+// Override
+func (file *testFile) Password() DecryptKey {
+	return file.password
+}
+
+// Override
+func (file *testFile) SetPassword(key DecryptKey) {
+	file.password = key
+}
+
+// Override
+func (file *testFile) String() string {
+	if file.url != nil {
+		return file.url.String()
+	}
+	return ""
+}
+
+// Override
+func (file *testFile) Serialize() any {
+	return file.Map()
+}
+
+type testFileHelper struct{}
+
+func (testFileHelper) SetTransportableFileFactory(factory TransportableFileFactory) {}
+
+func (testFileHelper) GetTransportableFileFactory() TransportableFileFactory {
+	return nil
+}
+
+func (testFileHelper) ParseTransportableFile(pnf any) TransportableFile {
+	return nil
+}
+
+func (testFileHelper) CreateTransportableFile(data TransportableData, filename string,
+	url URL, password DecryptKey) TransportableFile {
+	return newTestFile(data, filename, url, password)
+}
+
+// testKey is a toy symmetric key: XOR with the secret and a random-ish "IV"
+type testKey struct {
+	*Dictionary
+
+	secret []byte
+}
+
+var testKeyCounter uint32
+
+func newTestKey(secret string) *testKey {
+	return &testKey{
+		Dictionary: NewDictionary(StringKeyMap{"algorithm": "XOR"}),
+		secret:     []byte(secret),
+	}
+}
+
+// Override
+func (key *testKey) Algorithm() string {
+	return "XOR"
+}
+
+// Override
+func (key *testKey) Data() TransportableData {
+	return NewEncodedData(key.secret, nil)
+}
+
+// Override
+func (key *testKey) Encrypt(plaintext []byte, extra StringKeyMap) []byte {
+	iv := byte(atomic.AddUint32(&testKeyCounter, 1))
+	if extra != nil {
+		extra["IV"] = hex.EncodeToString([]byte{iv})
+	}
+	return key.xor(plaintext, iv)
+}
+
+// Override
+func (key *testKey) Decrypt(ciphertext []byte, params StringKeyMap) []byte {
+	iv, err := hex.DecodeString(FetchString(params["IV"]))
+	if err != nil || len(iv) != 1 {
+		return nil
+	}
+	return key.xor(ciphertext, iv[0])
+}
+
+// Override
+func (key *testKey) MatchEncryptKey(pKey EncryptKey) bool {
+	other, ok := pKey.(*testKey)
+	return ok && bytes.Equal(other.secret, key.secret)
+}
+
+func (key *testKey) xor(data []byte, iv byte) []byte {
+	out := make([]byte, len(data))
+	for index, b := range data {
+		out[index] = b ^ key.secret[index%len(key.secret)] ^ iv
+	}
+	return out
+}
+
+var _ SymmetricKey = (*testKey)(nil)
+var _ TransportableFile = (*testFile)(nil)
+
+func testTED(text string) TransportableData {
+	return NewEncodedData([]byte(text), nil)
+}
@@ -0,0 +1,108 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// LocalUploader stores file data in a local directory and returns "file://" URLs
+//
+// It is useful for tests and on-premises deployments that share a filesystem.
+// Each upload gets a random name (keeping the extension of the original filename),
+// and the file is written to a temporary file first, then renamed into place.
+type LocalUploader struct {
+	//Uploader
+
+	dir string
+}
+
+func NewLocalUploader(dir string) *LocalUploader {
+	return &LocalUploader{
+		dir: dir,
+	}
+}
+
+// Dir returns the directory for storing files
+func (uploader *LocalUploader) Dir() string {
+	return uploader.dir
+}
+
+// Override
+func (uploader *LocalUploader) Upload(data []byte, filename string) (URL, error) {
+	dir, err := filepath.Abs(uploader.dir)
+	if err != nil {
+		return nil, err
+	} else if err = os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	name, err := randomFilename(filepath.Ext(filename))
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, name)
+	if err = writeFileAtomic(path, data); err != nil {
+		return nil, err
+	}
+	return &url.URL{
+		Scheme: "file",
+		Path:   filepath.ToSlash(path),
+	}, nil
+}
+
+func randomFilename(ext string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf) + ext, nil
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory, then renames it
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpName, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpName)
+	}
+	return err
+}
@@ -0,0 +1,78 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"testing"
+
+	. "github.com/dimchat/mkm-go/format"
+)
+
+func TestSyncContentType(t *testing.T) {
+	// plain base64 data gets a data URI header
+	pnf := newTestFile(testTED("hello"), "a.txt", nil, nil)
+	SyncContentType(pnf, "text/plain")
+	if pnf.ContentType() != "text/plain" || pnf.Data().MimeType() != "text/plain" {
+		t.Errorf("plain data: %s, %s", pnf.ContentType(), pnf.Data().MimeType())
+	}
+	if string(pnf.Data().Bytes()) != "hello" || pnf.Data().Encoding() != "base64" {
+		t.Errorf("data changed: %q, %s", pnf.Data().Bytes(), pnf.Data().Encoding())
+	}
+	// parameters kept, MIME type replaced
+	header := NewDataHeader("application/octet-stream", "base64")
+	header.SetFilename("a.png")
+	pnf.SetData(NewEncodedData([]byte("png"), header))
+	SyncContentType(pnf, "image/png")
+	if got := pnf.Data().String(); got != "data:image/png;filename=a.png;base64,cG5n" {
+		t.Errorf("data URI: %s", got)
+	}
+	// without embedded data
+	file := newTestFile(nil, "a.png", nil, nil)
+	SyncContentType(file, "image/png")
+	if file.ContentType() != "image/png" || file.Data() != nil {
+		t.Errorf("no data: %s, %v", file.ContentType(), file.Data())
+	}
+}
+
+func TestEncryptAndUploadContentType(t *testing.T) {
+	uploader := NewLocalUploader(t.TempDir())
+	// magic bytes win over a wrong extension
+	pnf, err := EncryptAndUpload([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"), "avatar.jpg", newTestKey("k"), uploader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pnf.ContentType() != "image/png" {
+		t.Errorf("content type: %s", pnf.ContentType())
+	}
+	// UTF-16 text is not taken as MPEG audio
+	pnf, err = EncryptAndUpload([]byte("\xFF\xFEh\x00i\x00"), "notes.txt", newTestKey("k"), uploader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pnf.ContentType() != "text/plain" {
+		t.Errorf("content type: %s", pnf.ContentType())
+	}
+}
@@ -0,0 +1,123 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// StreamAlgorithm is the name of the chunk encryption construction
+//
+//	AES-256-GCM in STREAM mode:
+//	    key   = HKDF-SHA256(ikm = password.Data(), salt, info = "pnf-stream")
+//	    nonce = prefix (7 bytes) + counter (4 bytes, big-endian) + last flag (1 byte)
+//
+// Each chunk is sealed independently, the counter prevents reordering,
+// and the last flag prevents truncation.
+const StreamAlgorithm = "AES-256-GCM-STREAM"
+
+const (
+	streamNoncePrefixSize = 7
+	streamSaltSize        = 32
+	streamKeySize         = 32
+)
+
+var ErrChunkDecrypt = errors.New("failed to decrypt chunk")
+
+type streamCipher struct {
+	aead   cipher.AEAD
+	prefix []byte
+}
+
+func newStreamCipher(ikm, salt, prefix []byte) (*streamCipher, error) {
+	if len(ikm) == 0 {
+		return nil, errors.New("stream key material not found")
+	} else if len(prefix) != streamNoncePrefixSize {
+		return nil, fmt.Errorf("stream nonce prefix length error: %d", len(prefix))
+	}
+	key := hkdfSHA256(ikm, salt, []byte("pnf-stream"), streamKeySize)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &streamCipher{
+		aead:   aead,
+		prefix: prefix,
+	}, nil
+}
+
+func (sc *streamCipher) nonce(index int, last bool) []byte {
+	nonce := make([]byte, sc.aead.NonceSize())
+	copy(nonce, sc.prefix)
+	binary.BigEndian.PutUint32(nonce[streamNoncePrefixSize:], uint32(index))
+	if last {
+		nonce[len(nonce)-1] = 1
+	}
+	return nonce
+}
+
+func (sc *streamCipher) seal(index int, last bool, plaintext []byte) []byte {
+	return sc.aead.Seal(nil, sc.nonce(index, last), plaintext, nil)
+}
+
+func (sc *streamCipher) open(index int, last bool, ciphertext []byte) ([]byte, error) {
+	plaintext, err := sc.aead.Open(nil, sc.nonce(index, last), ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: #%d", ErrChunkDecrypt, index)
+	}
+	return plaintext, nil
+}
+
+// hkdfSHA256 derives key with HKDF (RFC 5869)
+func hkdfSHA256(ikm, salt, info []byte, length int) []byte {
+	if salt == nil {
+		salt = make([]byte, sha256.Size)
+	}
+	extractor := hmac.New(sha256.New, salt)
+	extractor.Write(ikm)
+	prk := extractor.Sum(nil)
+	okm := make([]byte, 0, length+sha256.Size)
+	var block []byte
+	for counter := byte(1); len(okm) < length; counter++ {
+		expander := hmac.New(sha256.New, prk)
+		expander.Write(block)
+		expander.Write(info)
+		expander.Write([]byte{counter})
+		block = expander.Sum(nil)
+		okm = append(okm, block...)
+	}
+	return okm[:length]
+}
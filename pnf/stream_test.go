@@ -0,0 +1,231 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// RFC 5869, Appendix A
+func TestHKDFVectors(t *testing.T) {
+	ikm := bytes.Repeat([]byte{0x0b}, 22)
+	cases := []struct {
+		salt     string
+		info     string
+		expected string
+	}{
+		{
+			"000102030405060708090a0b0c",
+			"f0f1f2f3f4f5f6f7f8f9",
+			"3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf34007208d5b887185865",
+		},
+		{
+			"",
+			"",
+			"8da4e775a563c18f715f802a063c5a31b8a11f5c5ee1879ec3454e5f3c738d2d9d201395faa4b61a96c8",
+		},
+	}
+	for _, item := range cases {
+		var salt []byte
+		if item.salt != "" {
+			salt, _ = hex.DecodeString(item.salt)
+		}
+		info, _ := hex.DecodeString(item.info)
+		okm := hkdfSHA256(ikm, salt, info, 42)
+		if got := hex.EncodeToString(okm); got != item.expected {
+			t.Errorf("HKDF: got %s, want %s", got, item.expected)
+		}
+	}
+}
+
+func TestStreamCipher(t *testing.T) {
+	prefix := []byte("1234567")
+	sc, err := newStreamCipher([]byte("password"), []byte("salt"), prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := sc.seal(0, false, []byte("first"))
+	last := sc.seal(1, true, []byte("last"))
+	if data, err := sc.open(0, false, first); err != nil || string(data) != "first" {
+		t.Errorf("open first: %q, %v", data, err)
+	}
+	if data, err := sc.open(1, true, last); err != nil || string(data) != "last" {
+		t.Errorf("open last: %q, %v", data, err)
+	}
+	// reordered
+	if _, err := sc.open(1, false, first); !errors.Is(err, ErrChunkDecrypt) {
+		t.Errorf("reordered chunk opened: %v", err)
+	}
+	// truncated: a middle chunk cannot be taken as the last one
+	if _, err := sc.open(0, true, first); !errors.Is(err, ErrChunkDecrypt) {
+		t.Errorf("truncated stream opened: %v", err)
+	}
+	// wrong key
+	other, _ := newStreamCipher([]byte("wrong"), []byte("salt"), prefix)
+	if _, err := other.open(0, false, first); !errors.Is(err, ErrChunkDecrypt) {
+		t.Errorf("opened with wrong key: %v", err)
+	}
+	if _, err := newStreamCipher(nil, nil, prefix); err == nil {
+		t.Errorf("expected error for empty key material")
+	}
+	if _, err := newStreamCipher([]byte("password"), nil, []byte("short")); err == nil {
+		t.Errorf("expected error for short nonce prefix")
+	}
+}
+
+func uploadChunked(t *testing.T, data []byte, chunkSize int) (TransportableFile, *SchemeDownloader) {
+	t.Helper()
+	uploader := NewLocalUploader(t.TempDir())
+	pnf, err := EncryptAndUploadChunked(bytes.NewReader(data), "movie.bin", newTestKey("secret"), uploader, chunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	downloader := NewSchemeDownloader()
+	downloader.SetDownloader("file", NewLocalDownloader(uploader.Dir()))
+	return pnf, downloader
+}
+
+func TestChunkedRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, 99, 100, 101, 1000} {
+		data := bytes.Repeat([]byte{0xA5}, size)
+		for index := range data {
+			data[index] ^= byte(index)
+		}
+		pnf, downloader := uploadChunked(t, data, 100)
+		file, err := OpenChunkedFile(pnf, downloader)
+		if err != nil {
+			t.Fatalf("size %d: %v", size, err)
+		}
+		count := (size + 99) / 100
+		if count == 0 {
+			// empty file has one empty chunk
+			count = 1
+		}
+		if file.Count() != count {
+			t.Errorf("size %d: %d chunks, want %d", size, file.Count(), count)
+		}
+		var buf bytes.Buffer
+		next, err := file.WriteTo(&buf, 0)
+		if err != nil || next != file.Count() {
+			t.Fatalf("size %d: write to: %d, %v", size, next, err)
+		}
+		if !bytes.Equal(buf.Bytes(), data) {
+			t.Errorf("size %d: plaintext not match", size)
+		}
+		if pnf.GetInt64("size", -1) != int64(size) {
+			t.Errorf("size %d: size field %d", size, pnf.GetInt64("size", -1))
+		}
+	}
+}
+
+func TestChunkedTruncation(t *testing.T) {
+	pnf, downloader := uploadChunked(t, bytes.Repeat([]byte("x"), 250), 100)
+	chunks := FetchMap(pnf.Get("chunks"))
+	list := FetchList(chunks["list"])
+	// drop the last chunk and fix the manifest digest
+	chunks["list"] = list[:len(list)-1]
+	pnf.Set("chunks", chunks)
+	pnf.Set("manifest_digest", ManifestDigest(chunks))
+	file, err := OpenChunkedFile(pnf, downloader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	next, err := file.WriteTo(&buf, 0)
+	if !errors.Is(err, ErrChunkDecrypt) || next != file.Count()-1 {
+		t.Errorf("truncated file accepted: %d, %v", next, err)
+	}
+}
+
+func TestChunkedManifestTampered(t *testing.T) {
+	pnf, downloader := uploadChunked(t, []byte("hello"), 100)
+	chunks := FetchMap(pnf.Get("chunks"))
+	chunks["chunk_size"] = 1
+	pnf.Set("chunks", chunks)
+	if _, err := OpenChunkedFile(pnf, downloader); !errors.Is(err, ErrManifestMismatch) {
+		t.Errorf("tampered manifest accepted: %v", err)
+	}
+	if _, err := OpenChunkedFile(newTestFile(nil, "a", nil, nil), downloader); err == nil {
+		t.Errorf("expected error for plain PNF")
+	}
+}
+
+type flakyDownloader struct {
+	delegate Downloader
+	failures int
+}
+
+func (downloader *flakyDownloader) Download(remote URL) ([]byte, error) {
+	if downloader.failures > 0 {
+		downloader.failures--
+		return nil, fmt.Errorf("network error: %s", remote.String())
+	}
+	return downloader.delegate.Download(remote)
+}
+
+func TestChunkedResume(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 30)
+	pnf, downloader := uploadChunked(t, data, 100)
+	flaky := &flakyDownloader{delegate: downloader}
+	file, err := OpenChunkedFile(pnf, flaky)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err = buf.Write(mustChunk(t, file, 0)); err != nil {
+		t.Fatal(err)
+	}
+	flaky.failures = 1
+	next, err := file.WriteTo(&buf, 1)
+	if err == nil || next != 1 {
+		t.Fatalf("expected failure at chunk 1: %d, %v", next, err)
+	}
+	if next, err = file.WriteTo(&buf, next); err != nil || next != file.Count() {
+		t.Fatalf("resume: %d, %v", next, err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("resumed plaintext not match")
+	}
+	if _, err = file.Chunk(file.Count()); err == nil {
+		t.Errorf("expected error for index out of range")
+	}
+}
+
+func mustChunk(t *testing.T, file *ChunkedFile, index int) []byte {
+	t.Helper()
+	data, err := file.Chunk(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
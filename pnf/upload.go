@@ -0,0 +1,122 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"errors"
+	"io"
+
+	. "github.com/dimchat/mkm-go/crypto"
+	. "github.com/dimchat/mkm-go/format"
+	. "github.com/dimchat/mkm-go/protocol"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// Uploader defines the interface for storing encrypted file data on CDN
+type Uploader interface {
+
+	// Upload stores the (encrypted) file data
+	//
+	// Parameters:
+	//   - data: File data to be stored (ciphertext)
+	//   - filename: Original filename (e.g., "avatar.png"), for reference only
+	// Returns: Download URL of the stored data
+	Upload(data []byte, filename string) (URL, error)
+}
+
+// EncryptAndUpload encrypts file data with the symmetric key, uploads the ciphertext,
+// and returns a PNF with "URL", "filename" and "key"
+//
+// Extra parameters generated by the key while encrypting (e.g., "IV" for AES)
+// are copied into the PNF, so the receiver can decrypt with pnf.Map();
+// "content-type" is detected from magic bytes and filename, and
+// the integrity fields ("digest", "size", "cipher_digest", "cipher_size")
+// are computed over both plaintext and ciphertext
+//
+// Parameters:
+//   - data: Raw file content (plaintext)
+//   - filename: Name of the file (e.g., "avatar.png")
+//   - key: Symmetric key to encrypt file data
+//   - uploader: CDN uploader
+//
+// Returns: Finished PNF (without "data")
+func EncryptAndUpload(data []byte, filename string, key SymmetricKey, uploader Uploader) (TransportableFile, error) {
+	if key == nil {
+		return nil, errors.New("symmetric key not provided")
+	} else if uploader == nil {
+		return nil, errors.New("uploader not provided")
+	}
+	extra := NewMap()
+	ciphertext := key.Encrypt(data, extra)
+	if ciphertext == nil {
+		return nil, errors.New("failed to encrypt file data")
+	}
+	url, err := uploader.Upload(ciphertext, filename)
+	if err != nil {
+		return nil, err
+	} else if url == nil {
+		return nil, errors.New("upload URL not returned")
+	}
+	pnf := CreateTransportableFile(nil, filename, url, key)
+	for name, value := range extra {
+		if !pnf.Contains(name) {
+			pnf.Set(name, value)
+		}
+	}
+	SyncContentType(pnf, DetectMimeType(filename, data))
+	AttachDigest(pnf, data, ciphertext)
+	return pnf, nil
+}
+
+// SyncContentType sets "content-type" of the PNF,
+// and rebuilds the embedded data URI when its MIME type is different,
+// so the two fields will never disagree
+func SyncContentType(pnf TransportableFile, mimeType string) {
+	if pnf == nil || mimeType == "" {
+		return
+	}
+	pnf.SetContentType(mimeType)
+	ted := pnf.Data()
+	if ted == nil || ted.MimeType() == mimeType {
+		return
+	}
+	header := ted.Header()
+	if header == nil {
+		header = NewDataHeader(mimeType, ted.Encoding())
+	} else {
+		header.SetMimeType(mimeType)
+	}
+	pnf.SetData(NewEncodedData(ted.Bytes(), header))
+}
+
+// EncryptAndUploadReader reads all file data from the reader, then calls EncryptAndUpload()
+func EncryptAndUploadReader(reader io.Reader, filename string, key SymmetricKey, uploader Uploader) (TransportableFile, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return EncryptAndUpload(data, filename, key, uploader)
+}
@@ -0,0 +1,150 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package pnf
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/dimchat/mkm-go/types"
+)
+
+func TestLocalUploader(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cdn", "files")
+	uploader := NewLocalUploader(dir)
+	data := []byte("\x89PNG\r\n\x1a\n")
+	first, err := uploader.Upload(data, "avatar.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := uploader.Upload(data, "avatar.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.String() == second.String() {
+		t.Errorf("upload names not unique: %s", first.String())
+	}
+	remote, err := toNetURL(first)
+	if err != nil || remote.Scheme != "file" {
+		t.Fatalf("upload URL: %s, %v", first.String(), err)
+	}
+	path := filepath.FromSlash(remote.Path)
+	if filepath.Dir(path) != dir || filepath.Ext(path) != ".png" {
+		t.Errorf("upload path: %s", path)
+	}
+	if stored, _ := os.ReadFile(path); !bytes.Equal(stored, data) {
+		t.Errorf("stored data: %q", stored)
+	}
+	// no temporary files left
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".tmp-") {
+			t.Errorf("temporary file left: %s", entry.Name())
+		}
+	}
+	if len(entries) != 2 {
+		t.Errorf("files: %d", len(entries))
+	}
+}
+
+func TestEncryptAndUploadReader(t *testing.T) {
+	dir := t.TempDir()
+	uploader := NewLocalUploader(dir)
+	key := newTestKey("secret")
+	data := []byte("hello world")
+	pnf, err := EncryptAndUploadReader(bytes.NewReader(data), "hello.txt", key, uploader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pnf.Filename() != "hello.txt" || pnf.Password() != key || pnf.ContentType() != "text/plain" {
+		t.Errorf("PNF: %v", pnf.Map())
+	}
+	remote, _ := toNetURL(pnf.URL())
+	ciphertext, err := os.ReadFile(filepath.FromSlash(remote.Path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(ciphertext, data) {
+		t.Errorf("data not encrypted")
+	}
+	if plaintext := key.Decrypt(ciphertext, pnf.Map()); !bytes.Equal(plaintext, data) {
+		t.Errorf("plaintext: %q", plaintext)
+	}
+	if err = VerifyCiphertext(pnf, ciphertext); err != nil {
+		t.Error(err)
+	}
+	if err = VerifyPlaintext(pnf, data); err != nil {
+		t.Error(err)
+	}
+}
+
+type failingUploader struct {
+	err   error
+	calls int
+}
+
+func (uploader *failingUploader) Upload(data []byte, filename string) (URL, error) {
+	uploader.calls++
+	return nil, uploader.err
+}
+
+type brokenReader struct{}
+
+func (brokenReader) Read(p []byte) (int, error) {
+	return 0, errors.New("disk error")
+}
+
+func TestEncryptAndUploadReaderErrors(t *testing.T) {
+	key := newTestKey("secret")
+	uploader := &failingUploader{}
+	// reader error: nothing uploaded
+	if _, err := EncryptAndUploadReader(brokenReader{}, "a.txt", key, uploader); err == nil || err.Error() != "disk error" {
+		t.Errorf("reader error: %v", err)
+	}
+	if uploader.calls != 0 {
+		t.Errorf("uploaded after reader error")
+	}
+	// upload error
+	uploader.err = errors.New("quota exceeded")
+	if _, err := EncryptAndUploadReader(strings.NewReader("hi"), "a.txt", key, uploader); err != uploader.err {
+		t.Errorf("upload error: %v", err)
+	}
+	// no URL returned
+	uploader.err = nil
+	if _, err := EncryptAndUploadReader(strings.NewReader("hi"), "a.txt", key, uploader); err == nil {
+		t.Errorf("expected error for empty URL")
+	}
+	if _, err := EncryptAndUploadReader(strings.NewReader("hi"), "a.txt", nil, uploader); err == nil {
+		t.Errorf("expected error for nil key")
+	}
+	if _, err := EncryptAndUploadReader(strings.NewReader("hi"), "a.txt", key, nil); err == nil {
+		t.Errorf("expected error for nil uploader")
+	}
+}
@@ -0,0 +1,60 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package protocol
+
+// Bulletin defines the interface for group document
+//
+//	info: {
+//	    "name"           : "Group Name",
+//	    "founder"        : "{FOUNDER_ID}",   // user who created the group
+//	    "owner"          : "{OWNER_ID}",     // current owner (default: founder)
+//	    "administrators" : ["{ADMIN_ID}"],
+//	    "assistants"     : ["{BOT_ID}"],     // group bots
+//	    "time"           : 123
+//	}
+type Bulletin interface {
+	Document
+
+	// Name returns the group name
+	Name() string
+	SetName(name string)
+
+	// Founder returns the ID of the user who created the group
+	Founder() ID
+	SetFounder(founder ID)
+
+	// Owner returns the current owner ID (falls back to founder)
+	Owner() ID
+	SetOwner(owner ID)
+
+	// Administrators returns the admin user IDs
+	Administrators() []ID
+	SetAdministrators(admins []ID)
+
+	// Assistants returns the group bot IDs
+	Assistants() []ID
+	SetAssistants(bots []ID)
+}
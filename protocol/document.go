@@ -95,6 +95,12 @@ type TAI interface {
 // Example values: "visa", "bulletin", etc.
 type DocumentType = string
 
+const (
+	VISA     DocumentType = "visa"     // user document (name, avatar, encryption key)
+	PROFILE  DocumentType = "profile"  // general document
+	BULLETIN DocumentType = "bulletin" // group document (founder, assistants)
+)
+
 // Document defines the interface for User/Group profile documents
 //
 // Extends Mapper and TAI interfaces, representing a signed entity profile document
@@ -202,3 +208,63 @@ func DocumentRevert(documents []Document) []StringKeyMap {
 	}
 	return array
 }
+
+//
+//  Document Data
+//
+
+// documentDataCoder encodes the document properties into the signed "data" field
+var documentDataCoder ObjectCoder = nil
+
+// SetDocumentDataCoder selects the coder for encoding document properties before signing
+//
+// Use &CanonicalJSONCoder{} to make the "data" string reproducible across platforms;
+// when not set, the shared JSON coder is used.
+func SetDocumentDataCoder(coder ObjectCoder) {
+	documentDataCoder = coder
+}
+
+func GetDocumentDataCoder() ObjectCoder {
+	return documentDataCoder
+}
+
+// EncodeDocumentData encodes document properties into the "data" string to be signed
+//
+// Returns: empty string if the properties cannot be encoded (e.g., NaN values)
+func EncodeDocumentData(properties StringKeyMap) string {
+	coder := documentDataCoder
+	if coder == nil {
+		return JSONEncodeMap(properties)
+	}
+	return coder.Encode(properties)
+}
+
+// DecodeDocumentData decodes the "data" string back to document properties
+func DecodeDocumentData(data string) StringKeyMap {
+	coder := documentDataCoder
+	if coder == nil {
+		return JSONDecodeMap(data)
+	}
+	return FetchMap(coder.Decode(data))
+}
+
+// SignDocumentData encodes document properties with the selected data coder and signs them
+//
+// Document implementations should call this in TAI.Sign(), so that the "data"
+// field is built the same way on every platform.
+//
+// Parameters:
+//   - properties: Document properties to be encoded
+//   - sKey: Private signing key that matches meta.key
+//
+// Returns: Encoded "data" string and raw signature of its UTF-8 bytes,
+// empty string and nil signature if the properties cannot be encoded
+func SignDocumentData(properties StringKeyMap, sKey SignKey) (string, []byte) {
+	data := EncodeDocumentData(properties)
+	if data == "" {
+		//panic("failed to encode document properties")
+		return "", nil
+	}
+	signature := sKey.Sign(UTF8Encode(data))
+	return data, signature
+}
@@ -158,3 +158,31 @@ func SetMetaFactory(version MetaType, factory MetaFactory) {
 	helper := GetMetaHelper()
 	helper.SetMetaFactory(version, factory)
 }
+
+//
+//  Conveniences
+//
+
+// MetaMatchID checks whether the meta generates the ID
+//
+//	Rules:
+//	    1. meta must be valid (fingerprint signed by meta.key);
+//	    2. ID.name must equal to meta.seed (both empty when no seed);
+//	    3. address generated by meta must equal to ID.address.
+//
+// Returns: false when meta or ID is nil
+func MetaMatchID(meta Meta, did ID) bool {
+	if meta == nil || did == nil {
+		return false
+	} else if !meta.IsValid() {
+		return false
+	} else if meta.Seed() != did.Name() {
+		return false
+	}
+	address := did.Address()
+	if address == nil {
+		return false
+	}
+	generated := meta.GenerateAddress(address.Network())
+	return generated != nil && generated.String() == address.String()
+}
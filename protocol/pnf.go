@@ -40,8 +40,9 @@ import (
 //	Supported serialization formats (subset of TransportableResource):
 //	    2. "{URL}"
 //	    3. {
-//	        "data"     : "...",        // base64_encode(fileContent)
-//	        "filename" : "avatar.png",
+//	        "data"         : "...",        // base64_encode(fileContent)
+//	        "filename"     : "avatar.png",
+//	        "content-type" : "image/png",
 //
 //	        "URL"      : "http://...", // download from CDN (file may be encrypted)
 //	        "key"      : {             // symmetric key to decrypt file data
@@ -65,6 +66,13 @@ type TransportableFile interface {
 	Filename() string
 	SetFilename(filename string)
 
+	// ContentType returns the MIME type of the file (e.g., "image/png")
+	//
+	// Falls back to the MIME type of the embedded data URI when "content-type" not set;
+	// SetContentType() updates the header of embedded data as well, to keep them consistent
+	ContentType() string
+	SetContentType(mimeType string)
+
 	// URL returns the CDN download URL of the file
 	URL() URL
 	SetURL(url URL)
@@ -0,0 +1,106 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package protocol
+
+import (
+	"strings"
+
+	. "github.com/dimchat/mkm-go/format"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// ParseTransportableResource parses any of the four serialized shapes
+// into TED or PNF object
+//
+//	Dispatching:
+//	    0. "{BASE64_ENCODE}"                       -> TransportableData
+//	    1. "data:image/png;base64,{BASE64_ENCODE}" -> TransportableData
+//	    2. "https://..."                           -> TransportableFile
+//	    3. {...}                                   -> TransportableFile
+//
+// The result can be checked with a type switch:
+//
+//	switch res := ParseTransportableResource(info["avatar"]).(type) {
+//	case TransportableData:
+//	    // embedded data
+//	case TransportableFile:
+//	    // download from res.URL(), or use res.Data()
+//	default:
+//	    // nil: invalid resource
+//	}
+//
+// Returns: nil if the value is empty or not a transportable resource
+func ParseTransportableResource(res any) TransportableResource {
+	if res == nil {
+		return nil
+	}
+	switch v := res.(type) {
+	case TransportableData:
+		return v
+	case TransportableFile:
+		return v
+	case Mapper, StringKeyMap:
+		return nilFile(ParseTransportableFile(v))
+	}
+	// check for map
+	if dict := FetchMap(res); dict != nil {
+		return nilFile(ParseTransportableFile(dict))
+	}
+	// check for string
+	str := FetchString(res)
+	if str == "" {
+		return nil
+	} else if IsTransportableDataString(str) {
+		return nilData(ParseTransportableData(str))
+	}
+	return nilFile(ParseTransportableFile(str))
+}
+
+// IsTransportableDataString checks whether the string is TED (format 0 or 1),
+// otherwise it should be a URL string for PNF (format 2)
+func IsTransportableDataString(str string) bool {
+	if len(str) >= 5 && strings.EqualFold(str[:5], "data:") {
+		// data URI
+		return true
+	}
+	// URL must contain a scheme separator, which is not a base64 character
+	return !strings.Contains(str, "://")
+}
+
+// avoid returning typed nil in interface
+func nilData(ted TransportableData) TransportableResource {
+	if ted == nil {
+		return nil
+	}
+	return ted
+}
+
+func nilFile(pnf TransportableFile) TransportableResource {
+	if pnf == nil {
+		return nil
+	}
+	return pnf
+}
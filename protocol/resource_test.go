@@ -0,0 +1,189 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package protocol
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	. "github.com/dimchat/mkm-go/crypto"
+	. "github.com/dimchat/mkm-go/format"
+	. "github.com/dimchat/mkm-go/types"
+)
+
+type testBase64Coder struct{}
+
+func (testBase64Coder) Encode(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func (testBase64Coder) Decode(str string) []byte {
+	data, err := base64.StdEncoding.DecodeString(str)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// testTEDHelper parses TED strings, nil for malformed data
+type testTEDHelper struct{}
+
+func (testTEDHelper) SetTransportableDataFactory(factory TransportableDataFactory) {}
+
+func (testTEDHelper) GetTransportableDataFactory() TransportableDataFactory {
+	return EncodedDataFactory{}
+}
+
+func (testTEDHelper) ParseTransportableData(ted any) TransportableData {
+	if data, ok := ted.(TransportableData); ok {
+		return data
+	}
+	data := ParseEncodedData(FetchString(ted))
+	if data == nil || data.Bytes() == nil {
+		return nil
+	}
+	return data
+}
+
+// testFile only carries the source map or URL
+type testFile struct {
+	TransportableFile
+
+	info StringKeyMap
+	url  string
+}
+
+// testFileHelper parses PNF with "URL" or "data", and "http(s)://" strings
+type testFileHelper struct{}
+
+func (testFileHelper) SetTransportableFileFactory(factory TransportableFileFactory) {}
+
+func (testFileHelper) GetTransportableFileFactory() TransportableFileFactory {
+	return nil
+}
+
+func (testFileHelper) ParseTransportableFile(pnf any) TransportableFile {
+	if file, ok := pnf.(TransportableFile); ok {
+		return file
+	} else if info := FetchMap(pnf); info != nil {
+		if info["URL"] == nil && info["data"] == nil {
+			return nil
+		}
+		return &testFile{info: info}
+	} else if str := FetchString(pnf); strings.HasPrefix(str, "http") {
+		return &testFile{url: str}
+	}
+	return nil
+}
+
+func (testFileHelper) CreateTransportableFile(data TransportableData, filename string,
+	url URL, password DecryptKey) TransportableFile {
+	return nil
+}
+
+func init() {
+	SetBase64Coder(testBase64Coder{})
+	SetTransportableDataHelper(testTEDHelper{})
+	SetTransportableFileHelper(testFileHelper{})
+}
+
+func TestParseTransportableResource(t *testing.T) {
+	ted := NewEncodedData([]byte("hello"), nil)
+	pnf := &testFile{url: "https://example.com/a.png"}
+	cases := []struct {
+		name string
+		res  any
+		data string // expected TED bytes
+		url  string // expected PNF URL
+		info bool   // expected PNF map
+		same any    // expected the same object
+	}{
+		{name: "nil", res: nil},
+		{name: "empty string", res: ""},
+		{name: "format 0", res: "aGVsbG8=", data: "hello"},
+		{name: "format 0 with slash", res: "/w==", data: "\xff"},
+		{name: "format 1", res: "data:text/plain;base64,aGk=", data: "hi"},
+		{name: "format 1 upper case", res: "DATA:text/plain;base64,aGk=", data: "hi"},
+		{name: "format 2", res: "https://example.com/a.png", url: "https://example.com/a.png"},
+		{name: "format 3", res: StringKeyMap{"URL": "https://example.com/a.png"}, info: true},
+		{name: "format 3 mapper", res: NewDictionary(StringKeyMap{"data": "aGk="}), info: true},
+		{name: "TED object", res: ted, same: ted},
+		{name: "PNF object", res: pnf, same: pnf},
+		{name: "stringer", res: NewConstantString("aGk="), data: "hi"},
+		// helpers return nil: no typed nil in the interface
+		{name: "malformed base64", res: "!!!"},
+		{name: "unknown scheme", res: "ftp://example.com/a.png"},
+		{name: "unknown map", res: StringKeyMap{"name": "a.png"}},
+		{name: "number", res: 42},
+	}
+	for _, item := range cases {
+		res := ParseTransportableResource(item.res)
+		switch v := res.(type) {
+		case nil:
+			if item.data != "" || item.url != "" || item.info || item.same != nil {
+				t.Errorf("%s: got nil", item.name)
+			}
+		case TransportableData:
+			if item.same != nil {
+				if res != item.same {
+					t.Errorf("%s: object not kept", item.name)
+				}
+			} else if item.data == "" || string(v.Bytes()) != item.data {
+				t.Errorf("%s: got TED %q", item.name, v.Bytes())
+			}
+		case TransportableFile:
+			file, _ := v.(*testFile)
+			if item.same != nil {
+				if res != item.same {
+					t.Errorf("%s: object not kept", item.name)
+				}
+			} else if file == nil || file.url != item.url || (file.info != nil) != item.info {
+				t.Errorf("%s: got PNF %#v", item.name, v)
+			}
+		default:
+			t.Errorf("%s: unexpected resource %#v", item.name, v)
+		}
+	}
+}
+
+func TestIsTransportableDataString(t *testing.T) {
+	cases := map[string]bool{
+		"aGVsbG8=":                   true,
+		"a+b/c===":                   true,
+		"data:image/png;base64,aGk=": true,
+		"Data:,hi":                   true,
+		"data":                       true,
+		"https://example.com/a.png":  false,
+		"ftp://example.com":          false,
+		"cas://sha256/abcd":          false,
+	}
+	for str, expected := range cases {
+		if got := IsTransportableDataString(str); got != expected {
+			t.Errorf("IsTransportableDataString(%q): %v", str, got)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package protocol
+
+import (
+	"fmt"
+
+	. "github.com/dimchat/mkm-go/types"
+)
+
+/**
+ *  Document Validity Window
+ *
+ *      info: {
+ *          "time"       : 1700000000,  // signing time
+ *          "not_before" : 1700000000,  // OPTIONAL, valid from
+ *          "expires"    : 1731536000   // OPTIONAL, valid until (exclusive)
+ *      }
+ *
+ *  Both values are document properties, so they are covered by the signature.
+ */
+
+// DocumentNotBefore returns the "not_before" time (nil if not set)
+func DocumentNotBefore(doc Document) Time {
+	return documentTimeProperty(doc, "not_before")
+}
+
+// DocumentExpires returns the "expires" time (nil if not set)
+func DocumentExpires(doc Document) Time {
+	return documentTimeProperty(doc, "expires")
+}
+
+// SetDocumentValidity sets the validity window into document properties
+//
+// Nil time removes the property; the document needs to be signed again.
+func SetDocumentValidity(doc Document, notBefore, expires Time) {
+	setDocumentTimeProperty(doc, "not_before", notBefore)
+	setDocumentTimeProperty(doc, "expires", expires)
+}
+
+// DocumentIsExpired checks whether the document is expired at the time
+//
+// A malformed "expires" counts as expired.
+func DocumentIsExpired(doc Document, now Time) bool {
+	expires, err := parseDocumentTimeProperty(doc, "expires")
+	if err != nil {
+		return true
+	} else if TimeIsNil(expires) {
+		return false
+	}
+	return now.UnixNano() >= expires.UnixNano()
+}
+
+// CheckDocumentValidity checks whether the time is in the validity window of the document
+//
+// A present but malformed "not_before" or "expires" (e.g., 0, negative, not a number)
+// is an error, so a broken window never makes the document valid forever.
+//
+// Returns: nil if valid, otherwise error describing the reason
+func CheckDocumentValidity(doc Document, now Time) error {
+	notBefore, err := parseDocumentTimeProperty(doc, "not_before")
+	if err != nil {
+		return err
+	}
+	expires, err := parseDocumentTimeProperty(doc, "expires")
+	if err != nil {
+		return err
+	}
+	if !TimeIsNil(notBefore) && now.UnixNano() < notBefore.UnixNano() {
+		return fmt.Errorf("document not valid before %v", notBefore)
+	} else if !TimeIsNil(expires) && now.UnixNano() >= expires.UnixNano() {
+		return fmt.Errorf("document expired at %v", expires)
+	}
+	return nil
+}
+
+func documentTimeProperty(doc Document, name string) Time {
+	when, _ := parseDocumentTimeProperty(doc, name)
+	return when
+}
+
+// parseDocumentTimeProperty returns nil time if not set, or error if malformed
+func parseDocumentTimeProperty(doc Document, name string) (Time, error) {
+	value := doc.GetProperty(name)
+	if value == nil {
+		return nil, nil
+	}
+	when := ParseTime(value)
+	if TimeIsNil(when) {
+		return nil, fmt.Errorf("document %s malformed: %v", name, value)
+	}
+	return when, nil
+}
+
+func setDocumentTimeProperty(doc Document, name string, when Time) {
+	if TimeIsNil(when) {
+		doc.SetProperty(name, nil)
+	} else {
+		doc.SetProperty(name, TimeToFloat64(when))
+	}
+}
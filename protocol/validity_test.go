@@ -0,0 +1,126 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package protocol
+
+import (
+	"testing"
+
+	. "github.com/dimchat/mkm-go/types"
+)
+
+// testDocument only keeps properties
+type testDocument struct {
+	Document
+
+	properties StringKeyMap
+}
+
+func newTestDocument(properties StringKeyMap) *testDocument {
+	return &testDocument{properties: properties}
+}
+
+// Override
+func (doc *testDocument) GetProperty(name string) any {
+	return doc.properties[name]
+}
+
+// Override
+func (doc *testDocument) SetProperty(name string, value any) {
+	if value == nil {
+		delete(doc.properties, name)
+	} else {
+		doc.properties[name] = value
+	}
+}
+
+func TestSetDocumentValidity(t *testing.T) {
+	doc := newTestDocument(StringKeyMap{})
+	SetDocumentValidity(doc, TimeFromFloat64(1000), TimeFromFloat64(2000.5))
+	if doc.properties["not_before"] != 1000.0 || doc.properties["expires"] != 2000.5 {
+		t.Fatalf("properties: %v", doc.properties)
+	}
+	if TimeToFloat64(DocumentNotBefore(doc)) != 1000 || TimeToFloat64(DocumentExpires(doc)) != 2000.5 {
+		t.Errorf("validity: %v, %v", DocumentNotBefore(doc), DocumentExpires(doc))
+	}
+	SetDocumentValidity(doc, nil, TimeNil())
+	if len(doc.properties) != 0 {
+		t.Errorf("properties not removed: %v", doc.properties)
+	}
+	if DocumentNotBefore(doc) != nil || DocumentExpires(doc) != nil {
+		t.Errorf("validity not removed")
+	}
+}
+
+func TestDocumentValidityWindow(t *testing.T) {
+	doc := newTestDocument(StringKeyMap{"not_before": 1000, "expires": 2000})
+	cases := []struct {
+		now     float64
+		expired bool
+		valid   bool
+	}{
+		{999, false, false},
+		{1000, false, true},
+		{1999.9, false, true},
+		{2000, true, false}, // exclusive
+		{3000, true, false},
+	}
+	for _, item := range cases {
+		now := TimeFromFloat64(item.now)
+		if got := DocumentIsExpired(doc, now); got != item.expired {
+			t.Errorf("expired at %v: %v", item.now, got)
+		}
+		if err := CheckDocumentValidity(doc, now); (err == nil) != item.valid {
+			t.Errorf("valid at %v: %v", item.now, err)
+		}
+	}
+	// no window
+	unlimited := newTestDocument(StringKeyMap{})
+	if DocumentIsExpired(unlimited, TimeNow()) || CheckDocumentValidity(unlimited, TimeNow()) != nil {
+		t.Errorf("document without window not valid")
+	}
+}
+
+func TestDocumentValidityMalformed(t *testing.T) {
+	now := TimeFromFloat64(1500)
+	for _, value := range []any{0, -1, "never", StringKeyMap{"at": 2000}, []any{2000}} {
+		doc := newTestDocument(StringKeyMap{"expires": value})
+		if err := CheckDocumentValidity(doc, now); err == nil {
+			t.Errorf("malformed expires %v: expected error", value)
+		}
+		if !DocumentIsExpired(doc, now) {
+			t.Errorf("malformed expires %v: not expired", value)
+		}
+		doc = newTestDocument(StringKeyMap{"not_before": value})
+		if err := CheckDocumentValidity(doc, now); err == nil {
+			t.Errorf("malformed not_before %v: expected error", value)
+		}
+	}
+	// numeric strings are accepted
+	doc := newTestDocument(StringKeyMap{"expires": "2000"})
+	if err := CheckDocumentValidity(doc, now); err != nil {
+		t.Errorf("numeric string: %v", err)
+	}
+}
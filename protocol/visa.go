@@ -0,0 +1,63 @@
+/* license: https://mit-license.org
+ * ==============================================================================
+ * The MIT License (MIT)
+ *
+ * Copyright (c) 2026 Albert Moky
+ *
+ * Permission is hereby granted, free of charge, to any person obtaining a copy
+ * of this software and associated documentation files (the "Software"), to deal
+ * in the Software without restriction, including without limitation the rights
+ * to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+ * copies of the Software, and to permit persons to whom the Software is
+ * furnished to do so, subject to the following conditions:
+ *
+ * The above copyright notice and this permission notice shall be included in all
+ * copies or substantial portions of the Software.
+ *
+ * THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+ * IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+ * FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+ * AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+ * LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+ * OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+ * SOFTWARE.
+ * ==============================================================================
+ */
+package protocol
+
+import (
+	. "github.com/dimchat/mkm-go/crypto"
+)
+
+// Visa defines the interface for user document
+//
+//	Data structure: {
+//	    "did"       : "{EntityID}",
+//	    "type"      : "visa",
+//	    "data"      : "{JSON}",          // data = json_encode(info)
+//	    "signature" : "{BASE64_ENCODE}"  // signature = sign(data, SK);
+//	}
+//
+//	info: {
+//	    "name"   : "Moky",
+//	    "avatar" : "https://...",        // PNF
+//	    "key"    : {...},                // public key for encryption
+//	    "time"   : 123
+//	}
+type Visa interface {
+	Document
+
+	// Name returns the nickname of the user
+	Name() string
+	SetName(name string)
+
+	// PublicKey returns the public key for encrypting messages to the user
+	//
+	// Used when meta.key can only verify signatures (e.g., ECC)
+	PublicKey() EncryptKey
+	SetPublicKey(key EncryptKey)
+
+	// Avatar returns the user avatar as PNF
+	Avatar() TransportableFile
+	SetAvatar(avatar TransportableFile)
+}
@@ -26,6 +26,7 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"strconv"
 )
@@ -81,6 +82,9 @@ func (DataConverter) GetString(value any, defaultValue string) string {
 		return formatFloat(float64(v), 32)
 	case float64:
 		return formatFloat(v, 64)
+	// JSON number
+	case json.Number:
+		return v.String()
 	default:
 		// unknown type
 		return fmt.Sprintf("%v", value)
@@ -104,6 +108,11 @@ func (DataConverter) GetTime(value any, defaultValue Time) Time {
 	switch v := value.(type) {
 	case Time:
 		return v
+	case json.Number:
+		// integral timestamp, keep full precision
+		if seconds, err := v.Int64(); err == nil && seconds > 0 {
+			return TimeFromInt64(seconds)
+		}
 	}
 	seconds := ConvertFloat64(value, 0)
 	if seconds > 0 {
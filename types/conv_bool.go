@@ -26,6 +26,7 @@
 package types
 
 import (
+	"encoding/json"
 	"errors"
 	"strings"
 )
@@ -84,6 +85,13 @@ func (DataConverter) GetBool(value any, defaultValue bool) bool {
 	// float number
 	case float32, float64:
 		return v != 0.0
+	// JSON number
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return defaultValue
+		}
+		return f != 0.0
 	default:
 		// unknown type
 		return defaultValue
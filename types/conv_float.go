@@ -26,6 +26,7 @@
 package types
 
 import (
+	"encoding/json"
 	"errors"
 	"strconv"
 	"strings"
@@ -91,6 +92,9 @@ func convFloat64(value any) (float64, error) {
 			return 1, nil
 		}
 		return 0, nil
+	// JSON number
+	case json.Number:
+		return v.Float64()
 	// string
 	case string:
 		s := strings.TrimSpace(v)
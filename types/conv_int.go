@@ -26,6 +26,7 @@
 package types
 
 import (
+	"encoding/json"
 	"errors"
 	"strconv"
 	"strings"
@@ -118,6 +119,17 @@ func convInt64(value any) (int64, error) {
 			return 1, nil
 		}
 		return 0, nil
+	// JSON number
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			f, err := v.Float64()
+			if err != nil {
+				return 0, err
+			}
+			return int64(f), nil
+		}
+		return i, nil
 	// string
 	case string:
 		s := strings.TrimSpace(v)
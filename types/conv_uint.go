@@ -26,6 +26,7 @@
 package types
 
 import (
+	"encoding/json"
 	"errors"
 	"strconv"
 	"strings"
@@ -118,6 +119,17 @@ func convUint64(value any) (uint64, error) {
 			return 1, nil
 		}
 		return 0, nil
+	// JSON number
+	case json.Number:
+		i, err := strconv.ParseUint(v.String(), 10, 64)
+		if err != nil {
+			f, err := v.Float64()
+			if err != nil {
+				return 0, err
+			}
+			return uint64(f), nil
+		}
+		return i, nil
 	// string
 	case string:
 		s := strings.TrimSpace(v)
@@ -26,6 +26,7 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 )
@@ -135,6 +136,9 @@ func (DataWrapper) Unwrap(value any) any {
 		return UnwrapList(v)
 	case Stringer: // fmt.Stringer:
 		return v.String()
+	case json.Number:
+		// keep number, do not convert to string
+		return v
 	}
 	// other types
 	target, rv := ObjectReflectValue(value)